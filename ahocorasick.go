@@ -0,0 +1,228 @@
+package bits
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+/*
+*
+
+	A Match is an occurrence of a key in the scanned text. Start and End are
+	byte offsets, with End just past the last byte. KeyIndex is the index of
+	the key in the map, as returned by FrozenTrieMap.LookupIndex, so
+	FrozenTrieMap.ReverseLookup gives the key that matched.
+*/
+type Match struct {
+	Start    uint
+	End      uint
+	KeyIndex uint
+}
+
+/*
+*
+
+	AhoCorasick finds every occurrence of every key of a FrozenTrieMap in a
+	text in a single pass. The trie nodes are the states of the automaton.
+	For each node, indexed by its level-order index, a bit-packed array
+	holds its failure link (the node of the longest proper suffix of its
+	word that is in the trie), its output link (the nearest final node along
+	the failure links, plus one, or 0 if there is none), and its depth.
+*/
+type AhoCorasick struct {
+	keys      *FrozenTrieMap
+	trie      *FrozenTrie
+	links     BitString
+	nodeBits  uint
+	depthBits uint
+}
+
+/*
+*
+
+	Builds the failure and output links of the trie of the map. The trie
+	must not have tails, nor a folding table: the links follow the letters
+	as they are stored.
+*/
+func CreateAhoCorasick(ftm *FrozenTrieMap) (AhoCorasick, error) {
+	ft := &ftm.Ft
+	if ft.tails != nil {
+		return AhoCorasick{}, fmt.Errorf("ahocorasick: tails are not supported")
+	}
+	if ft.folding != nil {
+		return AhoCorasick{}, fmt.Errorf("ahocorasick: folding is not supported")
+	}
+	nodeCount := ft.GetNodeCount()
+	fail := make([]uint, nodeCount)
+	output := make([]uint, nodeCount)
+	depth := make([]uint, nodeCount)
+
+	// nodes are numbered in level order, so the links of shallower nodes
+	// are known by the time they are needed.
+	var maxDepth uint = 0
+	for index := uint(0); index < nodeCount; index++ {
+		node := ft.GetNodeByIndex(index)
+		for j := uint(0); j < node.GetChildCount(); j++ {
+			child := node.GetChild(j)
+			depth[child.index] = depth[index] + 1
+			if depth[child.index] > maxDepth {
+				maxDepth = depth[child.index]
+			}
+
+			if index != 0 {
+				state := fail[index]
+				for {
+					next, found := ft.child(ft.GetNodeByIndex(state), child.letter)
+					if found {
+						fail[child.index] = next.index
+						break
+					}
+					if state == 0 {
+						break
+					}
+					state = fail[state]
+				}
+			}
+
+			suffix := fail[child.index]
+			if suffix != 0 && ft.GetNodeByIndex(suffix).final {
+				output[child.index] = suffix + 1
+			} else {
+				output[child.index] = output[suffix]
+			}
+		}
+	}
+
+	ac := AhoCorasick{
+		keys:      ftm,
+		trie:      ft,
+		nodeBits:  getOffsetBits(nodeCount),
+		depthBits: getOffsetBits(maxDepth),
+	}
+	bw := BitWriter{}
+	bw.Write(ac.depthBits, 8)
+	for i := uint(0); i < nodeCount; i++ {
+		bw.Write(fail[i], ac.nodeBits)
+		bw.Write(output[i], ac.nodeBits)
+		bw.Write(depth[i], ac.depthBits)
+	}
+	ac.links.Init(bw.GetData())
+	return ac, nil
+}
+
+/*
+*
+
+	Returns the links, to be restored with Init.
+*/
+func (ac *AhoCorasick) GetData() string {
+	return ac.links.GetData()
+}
+
+/*
+*
+
+	Restores the automaton of the map from the string returned by GetData.
+*/
+func (ac *AhoCorasick) Init(ftm *FrozenTrieMap, data string) error {
+	if len(data) < 1 {
+		return fmt.Errorf("ahocorasick: data too short")
+	}
+	ac.keys = ftm
+	ac.trie = &ftm.Ft
+	ac.links.Init(data)
+	ac.nodeBits = getOffsetBits(ac.trie.GetNodeCount())
+	ac.depthBits = ac.links.Get(0, 8)
+	if uint(len(data))*8 < ac.linkPosition(ac.trie.GetNodeCount()) {
+		return fmt.Errorf("ahocorasick: data too short")
+	}
+	return nil
+}
+
+func (ac *AhoCorasick) linkPosition(index uint) uint {
+	return 8 + index*(2*ac.nodeBits+ac.depthBits)
+}
+
+func (ac *AhoCorasick) fail(index uint) uint {
+	return ac.links.Get(ac.linkPosition(index), ac.nodeBits)
+}
+
+func (ac *AhoCorasick) output(index uint) (uint, bool) {
+	out := ac.links.Get(ac.linkPosition(index)+ac.nodeBits, ac.nodeBits)
+	return out - 1, out != 0
+}
+
+func (ac *AhoCorasick) depth(index uint) uint {
+	return ac.links.Get(ac.linkPosition(index)+2*ac.nodeBits, ac.depthBits)
+}
+
+// Returns the state after reading letter in state.
+func (ac *AhoCorasick) next(state uint, letter byte) uint {
+	for {
+		if child, found := ac.trie.child(ac.trie.GetNodeByIndex(state), letter); found {
+			return child.index
+		}
+		if state == 0 {
+			return 0
+		}
+		state = ac.fail(state)
+	}
+}
+
+// Calls fn with every key ending in state, the byte before end.
+func (ac *AhoCorasick) report(state, end uint, fn func(Match)) {
+	index, found := state, state != 0 && ac.trie.GetNodeByIndex(state).final
+	if !found {
+		index, found = ac.output(state)
+	}
+	for found {
+		fn(Match{Start: end - ac.depth(index), End: end, KeyIndex: ac.keys.keys.Rank1(index)})
+		index, found = ac.output(index)
+	}
+}
+
+/*
+*
+
+	Returns every occurrence of a key in text, ordered by end, and by
+	decreasing length for the same end. The text is normalized like the
+	keys of the trie first, and the offsets are into the normalized text.
+*/
+func (ac *AhoCorasick) FindAll(text string) []Match {
+	var result []Match
+	text = ac.trie.normalizeWord(text)
+	var state uint = 0
+	for i := 0; i < len(text); i++ {
+		state = ac.next(state, text[i])
+		ac.report(state, uint(i+1), func(m Match) {
+			result = append(result, m)
+		})
+	}
+	return result
+}
+
+/*
+*
+
+	Calls fn with every occurrence of a key in the text read from r, in the
+	order of FindAll, with offsets into the normalized text as in FindAll.
+	Returns the first error of r other than io.EOF.
+*/
+func (ac *AhoCorasick) Scan(r io.Reader, fn func(Match)) error {
+	br := bufio.NewReader(ac.trie.normalizeReader(r))
+	var state uint = 0
+	var offset uint = 0
+	for {
+		letter, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		offset++
+		state = ac.next(state, letter)
+		ac.report(state, offset, fn)
+	}
+}
@@ -0,0 +1,59 @@
+package bits
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("broken")
+}
+
+func TestAhoCorasick(t *testing.T) {
+	useByteLetters(t)
+	keys := []string{"a", "ab", "bab", "bc", "bca", "c", "caa", "he", "her", "hers", "she"}
+	te := Trie{}
+	te.Init()
+	for _, key := range keys {
+		te.Insert(key)
+	}
+	ftm := createTestMap(&te)
+
+	built, err := CreateAhoCorasick(&ftm)
+	assert.Nil(t, err)
+	ac := AhoCorasick{}
+	assert.Nil(t, ac.Init(&ftm, built.GetData()))
+
+	text := "ushers abccab bcaab xyz hershe"
+	var expected []Match
+	for end := 1; end <= len(text); end++ {
+		for start := 0; start < end; start++ {
+			if index, found := ftm.LookupIndex(text[start:end]); found {
+				expected = append(expected, Match{uint(start), uint(end), index})
+			}
+		}
+	}
+	matches := ac.FindAll(text)
+	assert.Equal(t, expected, matches)
+	for _, m := range matches {
+		assert.Equal(t, text[m.Start:m.End], ftm.ReverseLookup(m.KeyIndex))
+	}
+	assert.True(t, sort.SliceIsSorted(matches, func(i, j int) bool {
+		return matches[i].End < matches[j].End
+	}))
+
+	var scanned []Match
+	assert.Nil(t, ac.Scan(strings.NewReader(text), func(m Match) {
+		scanned = append(scanned, m)
+	}))
+	assert.Equal(t, matches, scanned)
+
+	assert.Empty(t, ac.FindAll("xyz"))
+	assert.NotNil(t, ac.Scan(failingReader{}, func(Match) {}))
+}
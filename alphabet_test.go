@@ -5,6 +5,12 @@ import (
 )
 
 func TestAlphabet(t *testing.T) {
+	alphabet, bits := allowedCharacters, dataBits
+	t.Cleanup(func() {
+		SetAllowedCharacters(alphabet)
+		dataBits = bits
+	})
+
 	if len(mapCharToUint) != 27 {
 		t.Error("len(mapCharToUint) != 27")
 		t.Log(mapCharToUint)
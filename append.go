@@ -0,0 +1,161 @@
+package bits
+
+import "fmt"
+
+/*
+*
+
+	Returns a new trie holding the words of the trie plus keys, which must
+	be in increasing order and greater than every word of the trie once
+	normalized like them. The trie must have no tails, and must have been
+	built from keys inserted in increasing order, so that the last child of
+	each node holds its greatest letter and GetLastLexographicKey returns
+	its greatest word; returns an error if the path of that word shows
+	otherwise.
+
+	Keys greater than every word only add nodes to the right end of each
+	level, and children to the nodes on the path of the last word, which are
+	the last nodes of their levels. So the level-order encoding of each
+	level is copied from the old one as a range of bits, except for the
+	degree of its last node, and the new nodes are written after it. The
+	rank/select index of the encoding is likewise kept up to the first node
+	that gains children.
+*/
+func (t *FrozenTrie) AppendSorted(keys ...string) (*FrozenTrie, error) {
+	if t.tails != nil {
+		return nil, fmt.Errorf("append: tails are not supported")
+	}
+
+	// the path of the last word, both as frozen nodes and as builder nodes
+	// that the new keys are inserted under.
+	var last []byte
+	root := &TrieNode{}
+	path := []FrozenTrieNode{t.GetRoot()}
+	newChildren := []uint{0}
+	existing := map[*TrieNode]uint{root: 0}
+	node := root
+	for parent := path[0]; parent.GetChildCount() > 0; parent = path[len(path)-1] {
+		next := parent.GetChild(parent.GetChildCount() - 1)
+		for i := uint(0); i+1 < parent.GetChildCount(); i++ {
+			if parent.GetChild(i).letter >= next.letter {
+				return nil, fmt.Errorf("append: the trie was not built from sorted keys")
+			}
+		}
+		last = append(last, next.letter)
+
+		child := &TrieNode{letter: next.letter}
+		node.children = append(node.children, child)
+		existing[child] = uint(len(path))
+		node = child
+		path = append(path, next)
+		newChildren = append(newChildren, 0)
+	}
+
+	previous := string(last)
+	for _, key := range keys {
+		key = t.normalizeWord(key)
+		if key <= previous {
+			return nil, fmt.Errorf("append: key %q is not greater than %q", key, previous)
+		}
+		previous = key
+
+		node := root
+		for i := 0; i < len(key); i++ {
+			child := node.getChild(key[i])
+			if child == nil {
+				child = &TrieNode{letter: key[i]}
+				node.children = append(node.children, child)
+				if depth, ok := existing[node]; ok {
+					newChildren[depth]++
+				}
+			}
+			node = child
+		}
+		node.final = true
+	}
+
+	// the new nodes of each level, in level order. The root is not new.
+	levels := [][]*TrieNode{nil}
+	for level := []*TrieNode{root}; len(level) > 0; {
+		var next, added []*TrieNode
+		for _, node := range level {
+			for _, child := range node.children {
+				next = append(next, child)
+				if _, ok := existing[child]; !ok {
+					added = append(added, child)
+				}
+			}
+		}
+		levels = append(levels, added)
+		level = next
+	}
+
+	degrees := BitWriter{}
+	letters := BitWriter{}
+	degrees.Write(0x02, 2)
+	var nodeCount uint = 0
+	// the degrees are those of the trie up to the first node on the path
+	// that gains children.
+	unchanged := t.directory.Len()
+	// the old nodes of the level are [start, end).
+	var start, end uint = 0, 1
+	for depth := 0; start < end || depth < len(levels); depth++ {
+		if start < end {
+			first := t.directory.Select0(start+1) + 1
+			if depth < len(path) {
+				// the last node of the level is on the path, and may have
+				// new children.
+				lastStart := t.directory.Select0(end) + 1
+				degrees.WriteFrom(&t.data, first, lastStart-first)
+				if n := degrees.Len() + path[depth].GetChildCount(); newChildren[depth] > 0 && n < unchanged {
+					unchanged = n
+				}
+				for i := uint(0); i < path[depth].GetChildCount()+newChildren[depth]; i++ {
+					degrees.Write(1, 1)
+				}
+				degrees.Write(0, 1)
+			} else {
+				degrees.WriteFrom(&t.data, first, t.directory.Select0(end+1)+1-first)
+			}
+			letters.WriteFrom(&t.data, t.letterStart+start*dataBits, (end-start)*dataBits)
+			nodeCount += end - start
+		}
+
+		if depth < len(levels) {
+			for _, node := range levels[depth] {
+				for range node.children {
+					degrees.Write(1, 1)
+				}
+				degrees.Write(0, 1)
+				if node.final {
+					letters.Write(1, 1)
+				} else {
+					letters.Write(0, 1)
+				}
+				letters.Write(uint(node.letter), dataBits-1)
+				nodeCount++
+			}
+		}
+
+		// the old nodes of the next level are the children of this one.
+		if start < end {
+			firstNode := t.GetNodeByIndex(start)
+			lastNode := t.GetNodeByIndex(end - 1)
+			start, end = firstNode.firstChild, lastNode.firstChild+lastNode.childCount
+		}
+	}
+
+	letterBits := BitString{}
+	letterBits.Init(letters.GetData())
+	degrees.WriteFrom(&letterBits, 0, letters.Len())
+	t.writeTrailer(&degrees)
+	data := degrees.GetData()
+
+	result := &FrozenTrie{}
+	if err := result.InitWithRankSelect(data,
+		extendRankSelect(t.directory, data, nodeCount*2+1, unchanged), nodeCount); err != nil {
+		return nil, err
+	}
+	result.foldable = t.foldable
+	return result, nil
+}
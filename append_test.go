@@ -0,0 +1,67 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendSorted(t *testing.T) {
+	cases := []struct {
+		old, added []string
+	}{
+		{[]string{"alphapha", "apple", "hello", "jello", "lamp", "orange", "quiz"},
+			[]string{"quizz", "quizzes", "r", "zebra"}},
+		// the old trie is deeper than the path of its last word
+		{[]string{"abcdefgh", "b"}, []string{"ba", "bz", "c", "cdefghijkl"}},
+		{[]string{"abc"}, []string{"abcd", "abd", "b"}},
+		{nil, []string{"a", "b"}},
+		{[]string{"a", "b"}, nil},
+	}
+
+	for _, c := range cases {
+		old := freezeWords(t, c.old...)
+		appended, err := old.AppendSorted(c.added...)
+		assert.Nil(t, err)
+
+		expected := freezeWords(t, append(append([]string{}, c.old...), c.added...)...)
+		assert.Equal(t, expected.GetNodeCount(), appended.GetNodeCount(), c.added)
+		assert.Equal(t, expected.GetData(), appended.GetData(), c.added)
+		for _, word := range append(c.old, c.added...) {
+			assert.True(t, appended.Lookup(word), word)
+		}
+	}
+
+	old := freezeWords(t, "apple", "lamp")
+	_, err := old.AppendSorted("zebra", "orange")
+	assert.NotNil(t, err)
+	_, err = old.AppendSorted("lamp")
+	assert.NotNil(t, err)
+	_, err = old.AppendSorted("kiwi")
+	assert.NotNil(t, err)
+
+	// the last child of the root is not its greatest letter
+	unsorted := freezeWords(t, "lamp", "apple")
+	_, err = unsorted.AppendSorted("zebra")
+	assert.NotNil(t, err)
+
+	te := Trie{}
+	te.Init()
+	assert.Nil(t, te.SetNormalization(NFC))
+	te.Insert("dhamma")
+	te.Insert("pīti")
+	for _, kind := range []RankSelectKind{RankDirectoryKind, PlainKind, RRRKind} {
+		teData, _ := te.Encode()
+		base := FrozenTrie{}
+		assert.Nil(t, base.InitWithRankSelect(teData, CreateRankSelect(kind, teData, te.GetNodeCount()*2+1), te.GetNodeCount()))
+		_, err = base.AppendSorted("pi\u0304ti")
+		assert.NotNil(t, err)
+		appended, err := base.AppendSorted("pi\u0304tika", "sutta")
+		assert.Nil(t, err)
+		assert.Equal(t, kind, appended.GetDirectory().Kind())
+		assert.Equal(t, NFC, appended.GetNormalization())
+		assert.True(t, appended.Lookup("pītika"))
+		assert.True(t, appended.Lookup("sutta"))
+		assert.True(t, appended.Lookup("dhamma"))
+	}
+}
@@ -0,0 +1,258 @@
+package bits
+
+/*
+*
+
+	The number of bits summarized by each leaf of the range min-max tree.
+*/
+const BPBlockSize = 64
+
+/*
+*
+
+	BalancedParens navigates a tree encoded as balanced parentheses: a
+	depth-first walk writes a 1 bit when it enters a node and a 0 bit when it
+	leaves it. A node is identified by the position of its 1 bit.
+
+	The excess at position i is the number of 1 bits minus the number of 0
+	bits up to and including i, which is the depth of the node opened at i,
+	counting the root as 1. Navigation reduces to finding the nearest
+	position, forwards or backwards, where the excess falls to a given value.
+	A range min-max tree keeps the minimum excess of each block of
+	BPBlockSize bits in a complete binary tree, so a search scans at most two
+	blocks and walks O(log n) tree nodes.
+*/
+type BalancedParens struct {
+	bits      BitString
+	directory RankSelect
+	numBits   uint
+	// the excess at the last position of each block
+	blockExcess []int
+	// the minimum excess of each subtree of blocks. The leaves, one per
+	// block, start at index leaves.
+	tree   []int
+	leaves int
+}
+
+/*
+*
+
+	Initializes the parentheses from the first numBits bits of data, and
+	builds the range min-max tree.
+
+	@param directory A RankSelect over the same bits.
+*/
+func (bp *BalancedParens) Init(data string, directory RankSelect, numBits uint) {
+	bp.bits.Init(data)
+	bp.directory = directory
+	bp.numBits = numBits
+
+	numBlocks := int((numBits + BPBlockSize - 1) / BPBlockSize)
+	bp.leaves = 1
+	for bp.leaves < numBlocks {
+		bp.leaves *= 2
+	}
+	bp.blockExcess = make([]int, numBlocks)
+	bp.tree = make([]int, 2*bp.leaves)
+	for i := range bp.tree {
+		// empty blocks can never hold the excess searched for.
+		bp.tree[i] = int(numBits) + 1
+	}
+
+	excess := 0
+	for b := 0; b < numBlocks; b++ {
+		min := int(numBits) + 1
+		for i := uint(b) * BPBlockSize; i < uint(b+1)*BPBlockSize && i < numBits; i++ {
+			excess += bp.step(i)
+			if excess < min {
+				min = excess
+			}
+		}
+		bp.blockExcess[b] = excess
+		bp.tree[bp.leaves+b] = min
+	}
+	for i := bp.leaves - 1; i > 0; i-- {
+		bp.tree[i] = bp.tree[2*i]
+		if bp.tree[2*i+1] < bp.tree[i] {
+			bp.tree[i] = bp.tree[2*i+1]
+		}
+	}
+}
+
+// +1 for an opening parenthesis, -1 for a closing one
+func (bp *BalancedParens) step(i uint) int {
+	if bp.bits.Get(i, 1) == 1 {
+		return 1
+	}
+	return -1
+}
+
+/*
+*
+
+	Returns the number of bits.
+*/
+func (bp *BalancedParens) Len() uint {
+	return bp.numBits
+}
+
+/*
+*
+
+	Returns true if position i holds an opening parenthesis.
+*/
+func (bp *BalancedParens) IsOpen(i uint) bool {
+	return i < bp.numBits && bp.bits.Get(i, 1) == 1
+}
+
+/*
+*
+
+	Returns the excess at position i.
+*/
+func (bp *BalancedParens) Excess(i uint) int {
+	return 2*int(bp.directory.Rank1(i)) - int(i) - 1
+}
+
+/*
+*
+
+	Returns the number of opening parentheses up to and including i. The
+	preorder number of the node opened at i, counting from 0, is one less.
+*/
+func (bp *BalancedParens) Rank(i uint) uint {
+	return bp.directory.Rank1(i)
+}
+
+/*
+*
+
+	Returns the position of the y'th opening parenthesis, counting from 1.
+*/
+func (bp *BalancedParens) Select(y uint) uint {
+	return bp.directory.Select1(y)
+}
+
+/*
+*
+
+	Returns the position of the parenthesis closing the one opened at i.
+*/
+func (bp *BalancedParens) FindClose(i uint) uint {
+	j, _ := bp.fwdSearch(i, -1)
+	return j
+}
+
+/*
+*
+
+	Returns the position of the parenthesis that opens the node enclosing
+	the node opened at i. found is false for the root.
+*/
+func (bp *BalancedParens) Enclose(i uint) (j uint, found bool) {
+	return bp.bwdSearch(i, -2)
+}
+
+/*
+*
+
+	Returns the smallest j > i whose excess is the excess at i plus d, for
+	d < 0.
+*/
+func (bp *BalancedParens) fwdSearch(i uint, d int) (uint, bool) {
+	target := bp.Excess(i) + d
+
+	// the rest of the block of i
+	excess := target - d
+	j := i + 1
+	for ; j%BPBlockSize != 0 && j < bp.numBits; j++ {
+		excess += bp.step(j)
+		if excess == target {
+			return j, true
+		}
+	}
+
+	// as the excess moves by one at each step, the first block falling to
+	// the target or lower reaches exactly the target.
+	b := bp.firstBlock(1, 0, bp.leaves, int(i/BPBlockSize)+1, target)
+	if b < 0 {
+		return 0, false
+	}
+	excess = bp.blockExcess[b-1]
+	for j = uint(b) * BPBlockSize; ; j++ {
+		excess += bp.step(j)
+		if excess == target {
+			return j, true
+		}
+	}
+}
+
+/*
+*
+
+	Returns the largest j < i whose excess is the excess at i plus d, less
+	one, for d < 0, plus one. This is the position just after the last one
+	whose excess is the target, which is what Enclose and LevelAncestor
+	want; the position before the first bit has excess 0.
+*/
+func (bp *BalancedParens) bwdSearch(i uint, d int) (uint, bool) {
+	excess := bp.Excess(i)
+	target := excess + d
+	if target < 0 {
+		return 0, false
+	}
+
+	// excess holds the excess at j-1 while scanning backwards.
+	j := i
+	for ; j%BPBlockSize != 0; j-- {
+		excess -= bp.step(j)
+		if excess == target {
+			return j, true
+		}
+	}
+
+	b := bp.lastBlock(1, 0, bp.leaves, int(i/BPBlockSize), target)
+	if b < 0 {
+		// only the position before the first bit is left.
+		return 0, target == 0
+	}
+	excess = bp.blockExcess[b]
+	for j = uint(b+1)*BPBlockSize - 1; ; j-- {
+		if excess == target {
+			return j + 1, true
+		}
+		excess -= bp.step(j)
+	}
+}
+
+// Returns the first block at or after from, within the subtree of node
+// covering blocks [lo, hi), whose minimum excess is target or lower.
+func (bp *BalancedParens) firstBlock(node, lo, hi, from, target int) int {
+	if hi <= from || bp.tree[node] > target {
+		return -1
+	}
+	if hi-lo == 1 {
+		return lo
+	}
+	mid := (lo + hi) / 2
+	if b := bp.firstBlock(2*node, lo, mid, from, target); b >= 0 {
+		return b
+	}
+	return bp.firstBlock(2*node+1, mid, hi, from, target)
+}
+
+// Returns the last block before before, within the subtree of node
+// covering blocks [lo, hi), whose minimum excess is target or lower.
+func (bp *BalancedParens) lastBlock(node, lo, hi, before, target int) int {
+	if lo >= before || bp.tree[node] > target {
+		return -1
+	}
+	if hi-lo == 1 {
+		return lo
+	}
+	mid := (lo + hi) / 2
+	if b := bp.lastBlock(2*node+1, mid, hi, before, target); b >= 0 {
+		return b
+	}
+	return bp.lastBlock(2*node, lo, mid, before, target)
+}
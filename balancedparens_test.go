@@ -0,0 +1,65 @@
+package bits
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// randomParens returns the parentheses of a random tree of n nodes.
+func randomParens(r *rand.Rand, n int) []bool {
+	var parens []bool
+	open := 0
+	for opened := 0; opened < n || open > 0; {
+		// the root must stay open until the last node is added.
+		canOpen := opened < n && (opened == 0 || open > 0)
+		canClose := open > 1 || (open == 1 && opened == n)
+		if canOpen && (!canClose || r.Intn(2) == 0) {
+			parens = append(parens, true)
+			opened++
+			open++
+		} else {
+			parens = append(parens, false)
+			open--
+		}
+	}
+	return parens
+}
+
+func TestBalancedParens(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for _, n := range []int{1, 2, 40, 1000} {
+		parens := randomParens(r, n)
+		bw := BitWriter{}
+		for _, p := range parens {
+			if p {
+				bw.Write(1, 1)
+			} else {
+				bw.Write(0, 1)
+			}
+		}
+		bp := BalancedParens{}
+		bp.Init(bw.GetData(), CreateRankSelect(RankDirectoryKind, bw.GetData(), bw.Len()), bw.Len())
+		assert.Equal(t, uint(2*n), bp.Len())
+
+		var stack []uint
+		excess := 0
+		for i, p := range parens {
+			if p {
+				excess++
+				assert.Equal(t, excess, bp.Excess(uint(i)))
+				parent, found := bp.Enclose(uint(i))
+				assert.Equal(t, len(stack) > 0, found)
+				if found {
+					assert.Equal(t, stack[len(stack)-1], parent)
+				}
+				stack = append(stack, uint(i))
+			} else {
+				excess--
+				assert.Equal(t, uint(i), bp.FindClose(stack[len(stack)-1]))
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
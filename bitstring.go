@@ -1,5 +1,7 @@
 package bits
 
+import "fmt"
+
 /*
 *
 
@@ -89,6 +91,19 @@ func (bs *BitString) Get(p, n uint) uint {
 	}
 }
 
+/*
+*
+
+	Like Get, but reads up to 64 bits, in two reads of 32 bits or fewer.
+*/
+func (bs *BitString) Get64(p, n uint) uint64 {
+	if n > 32 {
+		high := uint64(bs.Get(p, n-32))
+		return high<<32 | uint64(bs.Get(p+n-32, 32))
+	}
+	return uint64(bs.Get(p, n))
+}
+
 /*
 *
 
@@ -126,3 +141,127 @@ func (bs *BitString) Rank(x uint) uint {
 
 	return rank
 }
+
+/*
+*
+
+	Reads a number written by BitWriter.WriteUnary at position *p, and
+	advances *p past it. Returns an error, leaving *p where it was, if the
+	code runs past the end of the data.
+*/
+func (bs *BitString) ReadUnary(p *uint) (uint, error) {
+	var n uint = 0
+	for q := *p; q < bs.length; q++ {
+		if bs.Get(q, 1) == 1 {
+			*p = q + 1
+			return n, nil
+		}
+		n++
+	}
+	return 0, fmt.Errorf("bitstring: unary code at %d runs past the end of the data", *p)
+}
+
+/*
+*
+
+	Reads a number written by BitWriter.WriteGamma at position *p, and
+	advances *p past it. Returns an error, leaving *p where it was, if the
+	data is truncated or the code is longer than 64 bits.
+*/
+func (bs *BitString) ReadGamma(p *uint) (uint64, error) {
+	q := *p
+	length, err := bs.ReadUnary(&q)
+	if err != nil {
+		return 0, err
+	}
+	n, err := bs.readLowBits(&q, length)
+	if err != nil {
+		return 0, err
+	}
+	*p = q
+	return n, nil
+}
+
+/*
+*
+
+	Reads a number written by BitWriter.WriteDelta at position *p, and
+	advances *p past it. Returns an error, leaving *p where it was, if the
+	data is truncated or the code is longer than 64 bits.
+*/
+func (bs *BitString) ReadDelta(p *uint) (uint64, error) {
+	q := *p
+	length, err := bs.ReadGamma(&q)
+	if err != nil {
+		return 0, err
+	}
+	n, err := bs.readLowBits(&q, uint(length-1))
+	if err != nil {
+		return 0, err
+	}
+	*p = q
+	return n, nil
+}
+
+/*
+*
+
+	Reads a number written by BitWriter.WriteRice with parameter k at
+	position *p, and advances *p past it. Returns an error, leaving *p where
+	it was, if the data is truncated or the number overflows 64 bits.
+*/
+func (bs *BitString) ReadRice(p *uint, k uint) (uint64, error) {
+	q := *p
+	high, err := bs.ReadUnary(&q)
+	if err != nil {
+		return 0, err
+	}
+	if k > 64 || (k > 0 && uint64(high) >= uint64(1)<<(64-k)) {
+		return 0, fmt.Errorf("bitstring: rice code at %d overflows 64 bits", *p)
+	}
+	if q+k > bs.length {
+		return 0, fmt.Errorf("bitstring: rice code at %d runs past the end of the data", *p)
+	}
+	n := uint64(high)<<k | bs.Get64(q, k)
+	*p = q + k
+	return n, nil
+}
+
+// reads the length low bits of a gamma or delta code at *p, below their
+// implicit leading 1 bit.
+func (bs *BitString) readLowBits(p *uint, length uint) (uint64, error) {
+	if length > 63 {
+		return 0, fmt.Errorf("bitstring: code at %d is longer than 64 bits", *p)
+	}
+	if *p+length > bs.length {
+		return 0, fmt.Errorf("bitstring: code at %d runs past the end of the data", *p)
+	}
+	n := uint64(1)<<length | bs.Get64(*p, length)
+	*p += length
+	return n, nil
+}
+
+/*
+*
+
+	Splits a string written by joinSections back into its count sections.
+*/
+func splitSections(data string, count int) ([]string, error) {
+	header := BitString{}
+	header.Init(data)
+	pos := uint(count) * 4
+	if uint(len(data)) < pos {
+		return nil, fmt.Errorf("bits: data too short for %d sections", count)
+	}
+
+	sections := make([]string, count)
+	for i := range sections {
+		size := header.Get(uint(i)*32, 32)
+		if pos+size > uint(len(data)) {
+			return nil, fmt.Errorf("bits: section %d overruns the data", i)
+		}
+		sections[i] = data[pos : pos+size]
+		pos += size
+	}
+	return sections, nil
+}
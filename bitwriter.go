@@ -39,6 +39,97 @@ func (bw *BitWriter) Write(data, numBits uint) {
 	}
 }
 
+/*
+*
+
+	Writes a value of up to 64 bits, in two writes of 32 bits or fewer, so
+	that it does not depend on the width of uint.
+*/
+func (bw *BitWriter) Write64(data uint64, numBits uint) {
+	if numBits > 32 {
+		bw.Write(uint(data>>32), numBits-32)
+		numBits = 32
+	}
+	bw.Write(uint(data&0xffffffff), numBits)
+}
+
+/*
+*
+
+	Copy n bits of bs, starting at position p.
+*/
+func (bw *BitWriter) WriteFrom(bs *BitString, p, n uint) {
+	for ; n > 32; n -= 32 {
+		bw.Write(bs.Get(p, 32), 32)
+		p += 32
+	}
+	bw.Write(bs.Get(p, n), n)
+}
+
+/*
+*
+
+	Write n in unary: n 0 bits followed by a 1 bit.
+*/
+func (bw *BitWriter) WriteUnary(n uint) {
+	for ; n > 0; n-- {
+		bw.bits = append(bw.bits, 0)
+	}
+	bw.bits = append(bw.bits, 1)
+}
+
+/*
+*
+
+	Write n with the Elias gamma code: the number of bits of n, less one, in
+	unary, followed by n itself without its leading 1 bit. Panics if n is
+	0, which has no code.
+*/
+func (bw *BitWriter) WriteGamma(n uint64) {
+	if n == 0 {
+		panic("bitwriter: the gamma code cannot encode 0; write n+1 instead")
+	}
+	length := bitLength(n)
+	bw.WriteUnary(length - 1)
+	bw.Write64(n, length-1)
+}
+
+/*
+*
+
+	Write n with the Elias delta code: the number of bits of n in gamma
+	code, followed by n itself without its leading 1 bit. Panics if n is 0.
+	Longer than gamma for small numbers, shorter for large ones.
+*/
+func (bw *BitWriter) WriteDelta(n uint64) {
+	if n == 0 {
+		panic("bitwriter: the delta code cannot encode 0; write n+1 instead")
+	}
+	length := bitLength(n)
+	bw.WriteGamma(uint64(length))
+	bw.Write64(n, length-1)
+}
+
+/*
+*
+
+	Write n with the Golomb-Rice code of parameter k: n >> k in unary,
+	followed by the k low bits of n. Best when n is usually close to 2^k.
+*/
+func (bw *BitWriter) WriteRice(n uint64, k uint) {
+	bw.WriteUnary(uint(n >> k))
+	bw.Write64(n, k)
+}
+
+/*
+*
+
+	Returns the number of bits written so far.
+*/
+func (bw *BitWriter) Len() uint {
+	return uint(len(bw.bits))
+}
+
 /*
 *
 
@@ -89,3 +180,27 @@ func (bw *BitWriter) GetDebugString(group uint) string {
 
 	return strings.Join(chars, "")
 }
+
+// number of bits in n, without leading zeros
+func bitLength(n uint64) uint {
+	var length uint = 0
+	for ; n > 0; n >>= 1 {
+		length++
+	}
+	return length
+}
+
+/*
+*
+
+	Concatenates byte strings behind a header of their 32-bit lengths, so
+	that several encoded structures can be stored as one string and split
+	apart again with splitSections.
+*/
+func joinSections(sections ...string) string {
+	header := BitWriter{}
+	for _, section := range sections {
+		header.Write(uint(len(section)), 32)
+	}
+	return header.GetData() + strings.Join(sections, "")
+}
@@ -0,0 +1,74 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniversalCodes(t *testing.T) {
+	numbers := []uint64{1, 2, 3, 4, 7, 8, 9, 100, 1023, 1024, 1 << 20, 1<<32 - 1, 1<<64 - 1}
+
+	bw := BitWriter{}
+	for _, n := range numbers {
+		bw.WriteUnary(uint(n % 40))
+		bw.WriteGamma(n)
+		bw.WriteDelta(n)
+		bw.WriteRice(n%5000, 3)
+	}
+	bw.WriteRice(0, 0)
+	bw.WriteUnary(0)
+
+	bs := BitString{}
+	bs.Init(bw.GetData())
+	var p uint = 0
+	for _, n := range numbers {
+		unary, err := bs.ReadUnary(&p)
+		assert.Nil(t, err)
+		assert.Equal(t, uint(n%40), unary)
+		gamma, err := bs.ReadGamma(&p)
+		assert.Nil(t, err)
+		assert.Equal(t, n, gamma)
+		delta, err := bs.ReadDelta(&p)
+		assert.Nil(t, err)
+		assert.Equal(t, n, delta)
+		rice, err := bs.ReadRice(&p, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, n%5000, rice)
+	}
+	rice, err := bs.ReadRice(&p, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), rice)
+	unary, err := bs.ReadUnary(&p)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(0), unary)
+	assert.Equal(t, bw.Len(), p)
+
+	gamma := BitWriter{}
+	gamma.WriteGamma(5)
+	assert.Equal(t, "00101", gamma.GetDebugString(8))
+}
+
+func TestUniversalCodesTruncated(t *testing.T) {
+	bw := BitWriter{}
+	bw.WriteDelta(1 << 40)
+	data := bw.GetData()
+
+	bs := BitString{}
+	bs.Init(data[:len(data)-2])
+	var p uint = 0
+	_, err := bs.ReadDelta(&p)
+	assert.NotNil(t, err)
+	assert.Equal(t, uint(0), p)
+
+	bs.Init("\x00\x00")
+	_, err = bs.ReadGamma(&p)
+	assert.NotNil(t, err)
+}
+
+func TestUniversalCodesZero(t *testing.T) {
+	bw := BitWriter{}
+	assert.PanicsWithValue(t, "bitwriter: the gamma code cannot encode 0; write n+1 instead", func() { bw.WriteGamma(0) })
+	assert.PanicsWithValue(t, "bitwriter: the delta code cannot encode 0; write n+1 instead", func() { bw.WriteDelta(0) })
+	assert.Equal(t, uint(0), bw.Len())
+}
@@ -0,0 +1,303 @@
+package bits
+
+import "fmt"
+
+/*
+*
+
+	Encode the trie with its topology in balanced parentheses, in depth-first
+	order, instead of LOUDS. The data of each node, in (dataBits) bits as in
+	Encode, follows in the same order. The parentheses are indexed by a
+	RankSelect of the given kind. The header records the number of
+	parentheses and the normalization of the keys. Returns a string to be
+	read with FrozenBPTrie.Init.
+*/
+func (t *Trie) EncodeBP(kind RankSelectKind) string {
+	parens := BitWriter{}
+	letters := BitWriter{}
+	var visit func(node *TrieNode)
+	visit = func(node *TrieNode) {
+		parens.Write(1, 1)
+		if node.final {
+			letters.Write(1, 1)
+		} else {
+			letters.Write(0, 1)
+		}
+		letters.Write(uint(node.letter), dataBits-1)
+		for _, child := range node.children {
+			visit(child)
+		}
+		parens.Write(0, 1)
+	}
+	visit(t.root)
+
+	rs := CreateRankSelect(kind, parens.GetData(), parens.Len())
+	header := BitWriter{}
+	header.Write(parens.Len(), 32)
+	t.writeHeader(&header)
+	return joinSections(header.GetData(), parens.GetData(), EncodeRankSelect(rs), letters.GetData())
+}
+
+/*
+*
+
+	FrozenBPTrie is a frozen trie whose topology is stored as balanced
+	parentheses. Unlike the LOUDS topology of FrozenTrie, it answers subtree
+	sizes, depths and ancestors directly, and numbers the nodes in
+	depth-first order, so the nodes below a prefix are numbered
+	consecutively.
+
+	A node is identified by the position of its opening parenthesis; the
+	root is 0.
+*/
+type FrozenBPTrie struct {
+	parens  BalancedParens
+	letters BitString
+	foldable
+	normalizer
+}
+
+/*
+*
+
+	Initializes the trie from the string returned by Trie.EncodeBP.
+*/
+func (f *FrozenBPTrie) Init(data string) error {
+	sections, err := splitSections(data, 4)
+	if err != nil {
+		return err
+	}
+	if len(sections[0]) < 6 {
+		return fmt.Errorf("bptrie: header too short")
+	}
+	header := BitString{}
+	header.Init(sections[0])
+	numBits := header.Get(0, 32)
+	if err := f.readHeader(&header, 32); err != nil {
+		return err
+	}
+	directory, err := DecodeRankSelect(sections[2], sections[1], numBits)
+	if err != nil {
+		return err
+	}
+	f.parens.Init(sections[1], directory, numBits)
+	f.letters.Init(sections[3])
+	return nil
+}
+
+/*
+*
+
+	Returns the number of nodes in the trie.
+*/
+func (f *FrozenBPTrie) GetNodeCount() uint {
+	return f.parens.Len() / 2
+}
+
+/*
+*
+
+	Returns the number of the node in depth-first order, counting the root
+	as 0.
+*/
+func (f *FrozenBPTrie) Index(node uint) uint {
+	return f.parens.Rank(node) - 1
+}
+
+/*
+*
+
+	Returns the node with the given depth-first number.
+*/
+func (f *FrozenBPTrie) NodeByIndex(index uint) uint {
+	return f.parens.Select(index + 1)
+}
+
+/*
+*
+
+	Returns the letter of the edge leading into the node.
+*/
+func (f *FrozenBPTrie) Letter(node uint) byte {
+	return byte(f.letters.Get(f.Index(node)*dataBits+1, dataBits-1))
+}
+
+/*
+*
+
+	Returns true if the path to the node is a word.
+*/
+func (f *FrozenBPTrie) IsFinal(node uint) bool {
+	return f.letters.Get(f.Index(node)*dataBits, 1) == 1
+}
+
+/*
+*
+
+	Returns the parent of the node. found is false for the root.
+*/
+func (f *FrozenBPTrie) Parent(node uint) (parent uint, found bool) {
+	return f.parens.Enclose(node)
+}
+
+/*
+*
+
+	Returns the first child of the node. found is false for a leaf.
+*/
+func (f *FrozenBPTrie) FirstChild(node uint) (child uint, found bool) {
+	return node + 1, f.parens.IsOpen(node + 1)
+}
+
+/*
+*
+
+	Returns the next sibling of the node. found is false for the last child.
+*/
+func (f *FrozenBPTrie) NextSibling(node uint) (sibling uint, found bool) {
+	sibling = f.parens.FindClose(node) + 1
+	return sibling, f.parens.IsOpen(sibling)
+}
+
+/*
+*
+
+	Returns the number of nodes in the subtree of the node, including itself.
+*/
+func (f *FrozenBPTrie) SubtreeSize(node uint) uint {
+	return (f.parens.FindClose(node) - node + 1) / 2
+}
+
+/*
+*
+
+	Returns the depth of the node, which is the length of its word. The root
+	has depth 0.
+*/
+func (f *FrozenBPTrie) Depth(node uint) uint {
+	return uint(f.parens.Excess(node) - 1)
+}
+
+/*
+*
+
+	Returns the ancestor of the node d levels up. LevelAncestor(node, 1) is
+	the parent. found is false if the node is less than d levels deep.
+*/
+func (f *FrozenBPTrie) LevelAncestor(node, d uint) (ancestor uint, found bool) {
+	if d == 0 {
+		return node, true
+	}
+	return f.parens.bwdSearch(node, -int(d)-1)
+}
+
+// Returns the child of node with the given letter.
+func (f *FrozenBPTrie) child(node uint, letter byte) (uint, bool) {
+	child, found := f.FirstChild(node)
+	for found && f.Letter(child) != letter {
+		child, found = f.NextSibling(child)
+	}
+	return child, found
+}
+
+// A node at the end of a spelling of the word passed to descend.
+type bpDescent struct {
+	node     uint
+	spelling string
+}
+
+/*
+*
+
+	Follows word, once normalized like the keys, from the root, or with a
+	folding table, every spelling that folds like it, the exact one first.
+	Positions are nodes.
+*/
+func (f *FrozenBPTrie) descend(word string) []bpDescent {
+	var result []bpDescent
+	word = f.normalizeWord(word)
+	f.folding.expand(word, 0, func(from int, letter byte) (int, bool) {
+		child, found := f.child(uint(from), letter)
+		return int(child), found
+	}, func(at int, spelling string, covered int) {
+		if covered == len(word) {
+			result = append(result, bpDescent{uint(at), spelling})
+		}
+	})
+	return result
+}
+
+/*
+*
+
+	Look-up a word in the trie. Returns true if and only if the word exists
+	in the trie.
+*/
+func (f *FrozenBPTrie) Lookup(word string) bool {
+	_, found := f.LookupIndex(word)
+	return found
+}
+
+/*
+*
+
+	Returns the depth-first number of the node of the word.
+*/
+func (f *FrozenBPTrie) LookupIndex(word string) (index uint, found bool) {
+	for _, match := range f.descend(word) {
+		if f.IsFinal(match.node) {
+			return f.Index(match.node), true
+		}
+	}
+	return 0, false
+}
+
+/*
+*
+
+	Given a word, returns array of words, prefix of which is word. See
+	FrozenTrie.GetSuggestedWords.
+*/
+func (f *FrozenBPTrie) GetSuggestedWords(word string, limit int) []string {
+	var result []string
+	for _, match := range f.descend(word) {
+		if len(result) > limit {
+			break
+		}
+		result = append(result, f.traverseSubTrie(match.node, match.spelling, limit-len(result))...)
+	}
+	return result
+}
+
+func (f *FrozenBPTrie) traverseSubTrie(node uint, prefix string, limit int) []string {
+	var result []string
+
+	var level []uint
+	level = append(level, node)
+	var prefixLevel []string
+	prefixLevel = append(prefixLevel, prefix)
+
+	for len(level) > 0 {
+		nodeNow := level[0]
+		level = level[1:]
+		prefixNow := prefixLevel[0]
+		prefixLevel = prefixLevel[1:]
+
+		// if the prefix is a legal word.
+		if f.IsFinal(nodeNow) {
+			result = append(result, prefixNow)
+			if len(result) > limit {
+				return result
+			}
+		}
+
+		child, found := f.FirstChild(nodeNow)
+		for found {
+			level = append(level, child)
+			prefixLevel = append(prefixLevel, prefixNow+string([]byte{f.Letter(child)}))
+			child, found = f.NextSibling(child)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,72 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBPTrie(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertInAlphabeticalOrder(&te)
+	te.Insert("quizz")
+	te.Insert("quizzes")
+
+	frozen, err := te.Freeze(BPTopology)
+	assert.Nil(t, err)
+	bp := frozen.(*FrozenBPTrie)
+	ft, err := te.Freeze(LOUDSTopology)
+	assert.Nil(t, err)
+
+	assert.Equal(t, te.GetNodeCount(), bp.GetNodeCount())
+	for _, word := range []string{"apple", "hello", "quiz", "quizz", "quizzes", "a", "quizze", "zebra", ""} {
+		assert.Equal(t, ft.Lookup(word), bp.Lookup(word), word)
+	}
+	for _, prefix := range []string{"", "a", "qu", "quizz", "x"} {
+		assert.Equal(t, ft.GetSuggestedWords(prefix, 20), bp.GetSuggestedWords(prefix, 20), prefix)
+	}
+
+	// check the navigation against the pointer trie, node by node in
+	// depth-first order.
+	var check func(node *TrieNode, bpNode uint, depth uint, ancestors []uint) uint
+	check = func(node *TrieNode, bpNode uint, depth uint, ancestors []uint) uint {
+		assert.Equal(t, node.letter, bp.Letter(bpNode))
+		assert.Equal(t, node.final, bp.IsFinal(bpNode))
+		assert.Equal(t, depth, bp.Depth(bpNode))
+		assert.Equal(t, bpNode, bp.NodeByIndex(bp.Index(bpNode)))
+		for d, ancestor := range ancestors {
+			got, found := bp.LevelAncestor(bpNode, uint(len(ancestors)-d))
+			assert.True(t, found)
+			assert.Equal(t, ancestor, got)
+		}
+		_, found := bp.LevelAncestor(bpNode, depth+1)
+		assert.False(t, found)
+
+		var size uint = 1
+		child, found := bp.FirstChild(bpNode)
+		for _, c := range node.children {
+			assert.True(t, found)
+			parent, ok := bp.Parent(child)
+			assert.True(t, ok)
+			assert.Equal(t, bpNode, parent)
+			size += check(c, child, depth+1, append(ancestors, bpNode))
+			child, found = bp.NextSibling(child)
+		}
+		assert.False(t, found)
+		assert.Equal(t, size, bp.SubtreeSize(bpNode))
+		return size
+	}
+	check(te.root, 0, 0, nil)
+
+	index, found := bp.LookupIndex("quizzes")
+	assert.True(t, found)
+	node := bp.NodeByIndex(index)
+	quiz, _ := bp.LevelAncestor(node, 3)
+	assert.Equal(t, uint(4), bp.Depth(quiz))
+	assert.Equal(t, uint(4), bp.SubtreeSize(quiz))
+
+	_, err = te.Freeze(Topology(9))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,128 @@
+package bits
+
+import (
+	"sort"
+	"strings"
+)
+
+/*
+*
+
+	CaseExceptions holds the original spellings of the keys of a case-folded
+	FrozenTrieMap that differ from their folded form. The key indices of the
+	exceptions are stored in an EliasFano sequence, and the spellings are
+	concatenated into a pool, with their start offsets in a second one.
+*/
+type CaseExceptions struct {
+	indices EliasFano
+	offsets EliasFano
+	pool    string
+}
+
+/*
+*
+
+	Fills the map with the words, normalized to the given form and case
+	folded, and keeps the original spellings in an exception table so that
+	ReverseLookup returns them. Lookups on the trie normalize and fold the
+	words they are given the same way, and the encoding records both. If
+	several words fold to the same key, the spelling of the first one is
+	kept. Returns an error if the form is unknown.
+*/
+func (f *FrozenTrieMap) CreateCaseFolded(words []string, form NormalizationForm) error {
+	n := normalizer{foldCase: true}
+	if err := n.SetNormalization(form); err != nil {
+		return err
+	}
+
+	originals := map[string]string{}
+	var folded []string
+	for _, word := range words {
+		key := n.normalizeWord(word)
+		if _, ok := originals[key]; !ok {
+			originals[key] = word
+			folded = append(folded, key)
+		}
+	}
+	sort.Strings(folded)
+
+	te := Trie{}
+	te.Init()
+	te.normalizer = n
+	for _, key := range folded {
+		te.Insert(key)
+	}
+	teData, _ := te.Encode()
+	if err := f.Create(teData, te.GetNodeCount()); err != nil {
+		return err
+	}
+
+	spellings := map[uint]string{}
+	var indices []uint64
+	for _, key := range folded {
+		if original := originals[key]; original != key {
+			index, _ := f.LookupIndex(key)
+			spellings[index] = original
+			indices = append(indices, uint64(index))
+		}
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	var offsets []uint64
+	var pool strings.Builder
+	for _, index := range indices {
+		offsets = append(offsets, uint64(pool.Len()))
+		pool.WriteString(spellings[uint(index)])
+	}
+	offsets = append(offsets, uint64(pool.Len()))
+
+	// both sequences are sorted, so this cannot fail.
+	exceptions := &CaseExceptions{pool: pool.String()}
+	exceptions.indices, _ = CreateEliasFano(indices)
+	exceptions.offsets, _ = CreateEliasFano(offsets)
+	f.exceptions = exceptions
+	return nil
+}
+
+/*
+*
+
+	Returns the exception table, to be restored with Init.
+*/
+func (c *CaseExceptions) GetData() string {
+	return joinSections(c.indices.GetData(), c.offsets.GetData(), c.pool)
+}
+
+/*
+*
+
+	Restores the exception table from the string returned by GetData.
+*/
+func (c *CaseExceptions) Init(data string) error {
+	sections, err := splitSections(data, 3)
+	if err != nil {
+		return err
+	}
+	if err := c.indices.Init(sections[0]); err != nil {
+		return err
+	}
+	if err := c.offsets.Init(sections[1]); err != nil {
+		return err
+	}
+	c.pool = sections[2]
+	return nil
+}
+
+/*
+*
+
+	Returns the original spelling of the key with the given index, if it is
+	an exception.
+*/
+func (c *CaseExceptions) Get(keyIndex uint) (string, bool) {
+	i, value, found := c.indices.NextGEQ(uint64(keyIndex))
+	if !found || value != uint64(keyIndex) {
+		return "", false
+	}
+	return c.pool[c.offsets.Get(i):c.offsets.Get(i+1)], true
+}
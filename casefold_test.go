@@ -0,0 +1,102 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseFolded(t *testing.T) {
+	useByteLetters(t)
+	built := FrozenTrieMap{}
+	assert.Nil(t, built.CreateCaseFolded([]string{"Dhamma", "sutta", "Sāvatthī", "NIBBĀNA", "dhamma", "vinaya"}, NoNormalization))
+	assert.Equal(t, uint(5), built.GetKeyCount())
+
+	ftm := FrozenTrieMap{}
+	assert.Nil(t, ftm.InitFromData(built.GetData()))
+	for _, m := range []*FrozenTrieMap{&built, &ftm} {
+		for _, word := range []string{"Dhamma", "dhamma", "DHAMMA", "sāvatthī", "Nibbāna", "Sutta"} {
+			assert.True(t, m.Ft.Lookup(word), word)
+		}
+		assert.False(t, m.Ft.Lookup("dhammā"))
+
+		for word, original := range map[string]string{
+			"dhamma": "Dhamma", "SUTTA": "sutta", "sāvatthī": "Sāvatthī",
+			"nibbāna": "NIBBĀNA", "Vinaya": "vinaya",
+		} {
+			index, found := m.LookupIndex(word)
+			assert.True(t, found, word)
+			assert.Equal(t, original, m.ReverseLookup(index))
+		}
+		assert.Equal(t, []string{"sutta", "sāvatthī"}, m.Ft.GetSuggestedWords("S", 10))
+	}
+
+	// a plain map keeps comparing bytes
+	te := Trie{}
+	te.Init()
+	te.Insert("Dhamma")
+	created := createTestMap(&te)
+	plain := FrozenTrieMap{}
+	assert.Nil(t, plain.InitFromData(created.GetData()))
+	assert.False(t, plain.Ft.Lookup("dhamma"))
+	assert.True(t, plain.Ft.Lookup("Dhamma"))
+}
+
+func TestCaseFoldedUnicode(t *testing.T) {
+	useByteLetters(t)
+	// full case folding, beyond lower case
+	built := FrozenTrieMap{}
+	assert.Nil(t, built.CreateCaseFolded([]string{"Straße", "ΛΌΓΟΣ"}, NoNormalization))
+	ftm := FrozenTrieMap{}
+	assert.Nil(t, ftm.InitFromData(built.GetData()))
+	for _, m := range []*FrozenTrieMap{&built, &ftm} {
+		for _, word := range []string{"STRASSE", "strasse", "straße", "Straße"} {
+			index, found := m.LookupIndex(word)
+			assert.True(t, found, word)
+			assert.Equal(t, "Straße", m.ReverseLookup(index))
+		}
+		// a final sigma folds like any other sigma
+		for _, word := range []string{"λόγος", "λόγοσ", "ΛΌΓΟΣ"} {
+			index, found := m.LookupIndex(word)
+			assert.True(t, found, word)
+			assert.Equal(t, "ΛΌΓΟΣ", m.ReverseLookup(index))
+		}
+		assert.False(t, m.Ft.Lookup("strase"))
+	}
+
+	// composed and decomposed spellings, in either case, meet
+	composed := "Sāvatthī"
+	decomposed := "sA\u0304VATTHI\u0304"
+	normalized := FrozenTrieMap{}
+	assert.Nil(t, normalized.CreateCaseFolded([]string{composed}, NFC))
+	restored := FrozenTrieMap{}
+	assert.Nil(t, restored.InitFromData(normalized.GetData()))
+	for _, m := range []*FrozenTrieMap{&normalized, &restored} {
+		assert.Equal(t, NFC, m.Ft.GetNormalization())
+		for _, word := range []string{composed, decomposed, "sāvatthī", "SĀVATTHĪ"} {
+			index, found := m.LookupIndex(word)
+			assert.True(t, found, word)
+			assert.Equal(t, composed, m.ReverseLookup(index))
+		}
+	}
+	// without a form, the case is folded but the accents keep their bytes
+	plain := FrozenTrieMap{}
+	assert.Nil(t, plain.CreateCaseFolded([]string{composed}, NoNormalization))
+	assert.True(t, plain.Ft.Lookup("SĀVATTHĪ"))
+	assert.False(t, plain.Ft.Lookup(decomposed))
+
+	// the other encodings record the folding too
+	te := Trie{}
+	te.Init()
+	te.normalizer = normalizer{normalization: NFC, foldCase: true}
+	te.Insert("Sāvatthī")
+	for _, topology := range []Topology{LOUDSTopology, BPTopology, TailTopology, PatriciaTopology, DAWGTopology, FastTopology} {
+		data, err := te.EncodeDictionary(topology, RankDirectoryKind)
+		assert.Nil(t, err)
+		dict, err := LoadDictionary(data)
+		assert.Nil(t, err)
+		assert.True(t, dict.Lookup(decomposed), topology)
+	}
+
+	assert.NotNil(t, normalized.CreateCaseFolded([]string{composed}, NFKD+1))
+}
@@ -0,0 +1,219 @@
+package bits
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+/*
+*
+
+	A Cursor walks down a FrozenTrie one character at a time, keeping the
+	positions reached after each step, so that extending the prefix by a
+	character costs one child lookup per byte of it and going back costs
+	nothing. Characters are normalized and folded like the words of Lookup:
+	with a folding table, the cursor follows every spelling that folds like
+	the prefix at once.
+
+	If the trie normalizes its keys, a character typed later may still
+	change the last ones, as a combining macron turns an a into ā. The
+	cursor matches the text up to its last normalization boundary once, and
+	only the characters after it, usually one, again at the next step.
+*/
+type Cursor struct {
+	trie  *FrozenTrie
+	typed []byte
+	// the state after each step, the first one at the root
+	steps []cursorStep
+}
+
+// The state of a cursor after a step.
+type cursorStep struct {
+	// the number of bytes typed, and of those before the last
+	// normalization boundary
+	typed, settled int
+	// the spellings of the normalized settled text found in the trie, and
+	// those of the whole text
+	frontier, matches []descent
+}
+
+/*
+*
+
+	Returns a cursor at the root of the trie, with an empty prefix.
+*/
+func (f *FrozenTrie) NewCursor() *Cursor {
+	root := []descent{{position: triePosition{node: f.GetRoot()}}}
+	return &Cursor{trie: f, steps: []cursorStep{{frontier: root, matches: root}}}
+}
+
+func (c *Cursor) last() *cursorStep {
+	return &c.steps[len(c.steps)-1]
+}
+
+/*
+*
+
+	Extends the prefix by r. Returns false, leaving the cursor unchanged,
+	if no word starts with the extended prefix. If the trie normalizes its
+	keys, the last characters are accepted too if a word starts with what
+	more characters may turn them into.
+*/
+func (c *Cursor) Step(r rune) bool {
+	last := c.last()
+	var encoded [utf8.UTFMax]byte
+	typed := append(c.typed, encoded[:utf8.EncodeRune(encoded[:], r)]...)
+	next := cursorStep{typed: len(typed), settled: last.settled, frontier: last.frontier}
+
+	pending := typed[last.settled:]
+	if end := c.boundary(pending); end > 0 {
+		next.frontier = c.trie.extend(next.frontier, c.trie.normalizeWord(string(pending[:end])))
+		if len(next.frontier) == 0 {
+			return false
+		}
+		next.settled += end
+		pending = pending[end:]
+	}
+
+	next.matches = next.frontier
+	if len(pending) > 0 {
+		rest := c.trie.normalizeWord(string(pending))
+		next.matches = c.trie.extend(next.frontier, rest)
+		if len(next.matches) == 0 && !c.mayBecome(next.frontier, rest) {
+			return false
+		}
+	}
+	c.typed = typed
+	c.steps = append(c.steps, next)
+	return true
+}
+
+// Returns the length of the start of text that characters typed after it
+// cannot change once normalized: up to its last normalization boundary,
+// or all of it if the keys are not normalized.
+func (c *Cursor) boundary(text []byte) int {
+	form, ok, _ := c.trie.normalization.unicodeForm()
+	if !ok {
+		return len(text)
+	}
+	if end := form.LastBoundary(text); end > 0 {
+		return end
+	}
+	return 0
+}
+
+// Returns true if a word continues from the frontier with characters that
+// text, the normalized characters after the last boundary, may become
+// once more are typed: those whose canonical decomposition, after
+// folding, starts with that of text.
+func (c *Cursor) mayBecome(frontier []descent, text string) bool {
+	f := c.trie
+	want := norm.NFD.String(f.folding.Fold(text))
+
+	// the bytes of a character are collected from spelling[pending:]
+	// until it is complete, as in GetFuzzyWords.
+	var visit func(p triePosition, spelling []byte, pending int, have string) bool
+	visit = func(p triePosition, spelling []byte, pending int, have string) bool {
+		if pending < len(spelling) && utf8.FullRune(spelling[pending:]) {
+			r, _ := utf8.DecodeRune(spelling[pending:])
+			have += norm.NFD.String(string(f.folding.foldRune(r)))
+			pending = len(spelling)
+			if strings.HasPrefix(have, want) {
+				return true
+			}
+			if !strings.HasPrefix(want, have) {
+				return false
+			}
+		}
+		found := false
+		f.forEachStep(p, func(letter byte, next triePosition) {
+			found = found || visit(next, append(spelling, letter), pending, have)
+		})
+		return found
+	}
+
+	for _, d := range frontier {
+		if visit(d.position, nil, 0, "") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+*
+
+	Removes the last character typed. Returns false at the root.
+*/
+func (c *Cursor) Back() bool {
+	if len(c.steps) == 1 {
+		return false
+	}
+	c.steps = c.steps[:len(c.steps)-1]
+	c.typed = c.typed[:c.last().typed]
+	return true
+}
+
+/*
+*
+
+	Returns true if the prefix is a word of the trie.
+*/
+func (c *Cursor) IsFinal() bool {
+	for _, match := range c.last().matches {
+		if match.position.final() {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+*
+
+	Returns the characters typed so far.
+*/
+func (c *Cursor) Prefix() string {
+	return string(c.typed)
+}
+
+/*
+*
+
+	Returns the characters that follow the prefix in the words of the trie,
+	as they are stored, each once, in the order of the children of the
+	nodes.
+*/
+func (c *Cursor) Children() []rune {
+	var result []rune
+	seen := map[rune]bool{}
+	var visit func(p triePosition, letters []byte)
+	visit = func(p triePosition, letters []byte) {
+		if len(letters) > 0 && utf8.FullRune(letters) {
+			if r, _ := utf8.DecodeRune(letters); !seen[r] {
+				seen[r] = true
+				result = append(result, r)
+			}
+			return
+		}
+		c.trie.forEachStep(p, func(letter byte, next triePosition) {
+			visit(next, append(letters, letter))
+		})
+	}
+	for _, match := range c.last().matches {
+		visit(match.position, nil)
+	}
+	return result
+}
+
+/*
+*
+
+	Returns the words starting with the prefix, like GetSuggestedWords,
+	without descending from the root again.
+*/
+func (c *Cursor) Suggest(limit int) []string {
+	return c.trie.suggest(c.last().matches, limit)
+}
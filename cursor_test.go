@@ -0,0 +1,109 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertInAlphabeticalOrder(&te)
+
+	for _, ft := range []*FrozenTrie{freezeTrie(&te), freezeTrieWithTails(t, &te)} {
+		c := ft.NewCursor()
+		assert.False(t, c.Back())
+		assert.Equal(t, ft.GetSuggestedWords("", 20), c.Suggest(20))
+
+		for i, letter := range "hello" {
+			assert.True(t, c.Step(letter))
+			prefix := "hello"[:i+1]
+			assert.Equal(t, prefix, c.Prefix())
+			assert.Equal(t, ft.Lookup(prefix), c.IsFinal(), prefix)
+			assert.Equal(t, ft.GetSuggestedWords(prefix, 20), c.Suggest(20), prefix)
+		}
+		assert.Empty(t, c.Children())
+		assert.False(t, c.Step('x'))
+		assert.Equal(t, "hello", c.Prefix())
+
+		for i := 0; i < 4; i++ {
+			assert.True(t, c.Back())
+		}
+		assert.Equal(t, "h", c.Prefix())
+		assert.False(t, c.IsFinal())
+		assert.Equal(t, []rune("e"), c.Children())
+		for _, letter := range "ello" {
+			assert.True(t, c.Step(letter))
+		}
+		assert.True(t, c.IsFinal())
+		for c.Back() {
+		}
+		assert.Equal(t, "", c.Prefix())
+		root := ft.GetRoot()
+		assert.Equal(t, root.GetChildCount(), uint(len(c.Children())))
+	}
+}
+
+func TestCursorNormalized(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	assert.Nil(t, te.SetNormalization(NFC))
+	for _, word := range []string{"dhātu", "piya", "pīti", "pītika"} {
+		te.Insert(word)
+	}
+
+	for _, ft := range []*FrozenTrie{freezeTrie(&te), freezeTrieWithTails(t, &te)} {
+		c := ft.NewCursor()
+		for _, r := range "pi\u0304t" {
+			assert.True(t, c.Step(r), string(r))
+		}
+		assert.Equal(t, "pi\u0304t", c.Prefix())
+		assert.Equal(t, []string{"pīti", "pītika"}, c.Suggest(10))
+		assert.False(t, c.Step('x'))
+		assert.Equal(t, "pi\u0304t", c.Prefix())
+		assert.True(t, c.Step('i'))
+		assert.True(t, c.IsFinal())
+		// a dot below would compose with the i
+		assert.False(t, c.Step('\u0323'))
+		assert.Equal(t, []string{"pīti", "pītika"}, c.Suggest(10))
+
+		for i := 0; i < 3; i++ {
+			assert.True(t, c.Back())
+		}
+		assert.Equal(t, "pi", c.Prefix())
+		assert.Equal(t, []string{"piya"}, c.Suggest(10))
+		assert.True(t, c.Step('\u0304'))
+		assert.Equal(t, []string{"pīti", "pītika"}, c.Suggest(10))
+
+		// no word starts with dha, but one starts with what it may become
+		c = ft.NewCursor()
+		for _, r := range "dha" {
+			assert.True(t, c.Step(r), string(r))
+		}
+		assert.Empty(t, c.Suggest(10))
+		assert.False(t, c.Step('t'))
+		assert.True(t, c.Step('\u0304'))
+		assert.Equal(t, []string{"dhātu"}, c.Suggest(10))
+		assert.Equal(t, []rune("t"), c.Children())
+	}
+}
+
+func TestCursorFolded(t *testing.T) {
+	ft := freezeWords(t, "piya", "pīti", "pītika")
+	ft.SetFolding(NewFoldingTable(PaliFolding))
+	c := ft.NewCursor()
+	assert.True(t, c.Step('p'))
+	assert.Equal(t, []rune("iī"), c.Children())
+	for _, r := range "iti" {
+		assert.True(t, c.Step(r), string(r))
+	}
+	assert.True(t, c.IsFinal())
+	assert.Equal(t, []string{"pīti", "pītika"}, c.Suggest(10))
+	assert.False(t, c.Step('z'))
+	assert.True(t, c.Back())
+	assert.True(t, c.Back())
+	assert.Equal(t, []rune("yt"), c.Children())
+}
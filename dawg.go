@@ -0,0 +1,336 @@
+package bits
+
+import (
+	"bytes"
+	"fmt"
+)
+
+/*
+*
+
+	A node of the minimized trie built by Trie.EncodeDAWG.
+*/
+type dawgNode struct {
+	final    bool
+	letters  []byte
+	children []*dawgNode
+	keys     uint
+	id       int
+}
+
+/*
+*
+
+	Minimize the trie into a directed acyclic word graph, merging every set
+	of equivalent subtrees into one node, and encode it. Word lists with
+	many shared suffixes, like inflected forms, need far fewer nodes than in
+	a trie.
+
+	The encoding has three parts. The edges of each node, in breadth-first
+	order of the nodes, are written in unary: one 1 bit per edge and a 0 bit
+	after each node. Then one bit per node marks the final nodes. Then each
+	edge has (dataBits-1) bits for its letter, the number of the node it
+	leads to, and the number of keys that can be reached through it. The
+	first nodeCount+edgeCount bits are indexed by a RankSelect of the given
+	kind. The header records the counts and the normalization of the keys.
+	Returns a string to be read with FrozenDAWG.Init.
+*/
+func (t *Trie) EncodeDAWG(kind RankSelectKind) string {
+	root := minimize(t.root, map[string]*dawgNode{})
+
+	// number the nodes breadth first.
+	var nodes []*dawgNode
+	var edgeCount uint = 0
+	root.id = 0
+	nodes = append(nodes, root)
+	for i := 0; i < len(nodes); i++ {
+		for _, child := range nodes[i].children {
+			if child.id < 0 {
+				child.id = len(nodes)
+				nodes = append(nodes, child)
+			}
+		}
+		edgeCount += uint(len(nodes[i].children))
+	}
+	nodeCount := uint(len(nodes))
+	numKeys := root.keys
+
+	bits := BitWriter{}
+	for _, node := range nodes {
+		for range node.children {
+			bits.Write(1, 1)
+		}
+		bits.Write(0, 1)
+	}
+	for _, node := range nodes {
+		if node.final {
+			bits.Write(1, 1)
+		} else {
+			bits.Write(0, 1)
+		}
+	}
+
+	nodeBits := getOffsetBits(nodeCount)
+	keyBits := getOffsetBits(numKeys)
+	for _, node := range nodes {
+		for i, child := range node.children {
+			bits.Write(uint(node.letters[i]), dataBits-1)
+			bits.Write(uint(child.id), nodeBits)
+			bits.Write(child.keys, keyBits)
+		}
+	}
+
+	directory := CreateRankSelect(kind, bits.GetData(), nodeCount+edgeCount)
+	header := BitWriter{}
+	header.Write(nodeCount, 32)
+	header.Write(edgeCount, 32)
+	header.Write(numKeys, 32)
+	t.writeHeader(&header)
+	return joinSections(header.GetData(), bits.GetData(), EncodeRankSelect(directory))
+}
+
+// Returns the registered node equivalent to the subtree of node, creating
+// the nodes that are not registered yet.
+func minimize(node *TrieNode, register map[string]*dawgNode) *dawgNode {
+	result := &dawgNode{final: node.final, id: -1}
+	if node.final {
+		result.keys = 1
+	}
+	for _, child := range node.children {
+		minimized := minimize(child, register)
+		result.letters = append(result.letters, child.letter)
+		result.children = append(result.children, minimized)
+		result.keys += minimized.keys
+	}
+
+	// two subtrees are equivalent if they have the same finality and the
+	// same letters leading to the same registered nodes.
+	var signature bytes.Buffer
+	if result.final {
+		signature.WriteByte(1)
+	} else {
+		signature.WriteByte(0)
+	}
+	for i, child := range result.children {
+		fmt.Fprintf(&signature, "%c%p", result.letters[i], child)
+	}
+
+	if existing, ok := register[signature.String()]; ok {
+		return existing
+	}
+	register[signature.String()] = result
+	return result
+}
+
+/*
+*
+
+	FrozenDAWG looks up words in the encoding produced by Trie.EncodeDAWG.
+*/
+type FrozenDAWG struct {
+	data       BitString
+	directory  RankSelect
+	nodeCount  uint
+	finalStart uint
+	edgeStart  uint
+	edgeBits   uint
+	nodeBits   uint
+	keyBits    uint
+	foldable
+	normalizer
+}
+
+/*
+*
+
+	Initializes the graph from the string returned by Trie.EncodeDAWG.
+*/
+func (f *FrozenDAWG) Init(data string) error {
+	sections, err := splitSections(data, 3)
+	if err != nil {
+		return err
+	}
+	if len(sections[0]) < 14 {
+		return fmt.Errorf("dawg: header too short")
+	}
+	header := BitString{}
+	header.Init(sections[0])
+	nodeCount := header.Get(0, 32)
+	edgeCount := header.Get(32, 32)
+	numKeys := header.Get(64, 32)
+	if err := f.readHeader(&header, 96); err != nil {
+		return err
+	}
+
+	directory, err := DecodeRankSelect(sections[2], sections[1], nodeCount+edgeCount)
+	if err != nil {
+		return fmt.Errorf("dawg: %v", err)
+	}
+	f.data.Init(sections[1])
+	f.directory = directory
+	f.nodeCount = nodeCount
+	f.finalStart = nodeCount + edgeCount
+	f.edgeStart = f.finalStart + nodeCount
+	f.nodeBits = getOffsetBits(nodeCount)
+	f.keyBits = getOffsetBits(numKeys)
+	f.edgeBits = dataBits - 1 + f.nodeBits + f.keyBits
+	return nil
+}
+
+// the range of edges leaving node
+func (f *FrozenDAWG) edges(node uint) (first, end uint) {
+	var start uint = 0
+	if node > 0 {
+		start = f.directory.Select0(node) + 1
+	}
+	first = start - node
+	end = f.directory.Select0(node+1) - node
+	return first, end
+}
+
+func (f *FrozenDAWG) edge(e uint) (letter byte, target, keys uint) {
+	p := f.edgeStart + e*f.edgeBits
+	letter = byte(f.data.Get(p, dataBits-1))
+	target = f.data.Get(p+dataBits-1, f.nodeBits)
+	keys = f.data.Get(p+dataBits-1+f.nodeBits, f.keyBits)
+	return letter, target, keys
+}
+
+func (f *FrozenDAWG) isFinal(node uint) bool {
+	return f.data.Get(f.finalStart+node, 1) == 1
+}
+
+/*
+*
+
+	A position in the graph: a node, and the number of keys that come before
+	the letters leading to it in edge order.
+*/
+type dawgPosition struct {
+	node   uint
+	before uint
+}
+
+// Returns the position reached from p by the letter.
+func (f *FrozenDAWG) step(p dawgPosition, letter byte) (dawgPosition, bool) {
+	if f.isFinal(p.node) {
+		p.before++
+	}
+	first, end := f.edges(p.node)
+	for e := first; e < end; e++ {
+		edgeLetter, target, keys := f.edge(e)
+		if edgeLetter == letter {
+			return dawgPosition{target, p.before}, true
+		}
+		p.before += keys
+	}
+	return p, false
+}
+
+// A position at the end of a spelling of the word passed to descend.
+type dawgDescent struct {
+	position dawgPosition
+	spelling string
+}
+
+/*
+*
+
+	Follows word, once normalized like the keys, from the root, or with a
+	folding table, every spelling that folds like it, the exact one first.
+*/
+func (f *FrozenDAWG) descend(word string) []dawgDescent {
+	var result []dawgDescent
+	word = f.normalizeWord(word)
+	positions := []dawgPosition{{}}
+	f.folding.expand(word, 0, func(from int, letter byte) (int, bool) {
+		next, found := f.step(positions[from], letter)
+		if !found {
+			return 0, false
+		}
+		positions = append(positions, next)
+		return len(positions) - 1, true
+	}, func(at int, spelling string, covered int) {
+		if covered == len(word) {
+			result = append(result, dawgDescent{positions[at], spelling})
+		}
+	})
+	return result
+}
+
+/*
+*
+
+	Look-up a word in the graph. Returns true if and only if the word exists
+	in it.
+*/
+func (f *FrozenDAWG) Lookup(word string) bool {
+	_, found := f.LookupIndex(word)
+	return found
+}
+
+/*
+*
+
+	Returns the position of the word among the keys, counting from 1, in the
+	depth-first order of the edges. This is the order of the keys when the
+	trie was built from sorted words.
+*/
+func (f *FrozenDAWG) LookupIndex(word string) (index uint, found bool) {
+	for _, match := range f.descend(word) {
+		if f.isFinal(match.position.node) {
+			return match.position.before + 1, true
+		}
+	}
+	return 0, false
+}
+
+/*
+*
+
+	Given a word, returns array of words, prefix of which is word. See
+	FrozenTrie.GetSuggestedWords.
+*/
+func (f *FrozenDAWG) GetSuggestedWords(word string, limit int) []string {
+	var result []string
+	for _, match := range f.descend(word) {
+		if len(result) > limit {
+			break
+		}
+		result = append(result, f.traverseSubGraph(match.position.node, match.spelling, limit-len(result))...)
+	}
+	return result
+}
+
+func (f *FrozenDAWG) traverseSubGraph(node uint, prefix string, limit int) []string {
+	var result []string
+
+	var level []uint
+	level = append(level, node)
+	var prefixLevel []string
+	prefixLevel = append(prefixLevel, prefix)
+
+	for len(level) > 0 {
+		nodeNow := level[0]
+		level = level[1:]
+		prefixNow := prefixLevel[0]
+		prefixLevel = prefixLevel[1:]
+
+		// if the prefix is a legal word.
+		if f.isFinal(nodeNow) {
+			result = append(result, prefixNow)
+			if len(result) > limit {
+				return result
+			}
+		}
+
+		first, end := f.edges(nodeNow)
+		for e := first; e < end; e++ {
+			letter, target, _ := f.edge(e)
+			level = append(level, target)
+			prefixLevel = append(prefixLevel, prefixNow+string([]byte{letter}))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,47 @@
+package bits
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDAWG(t *testing.T) {
+	useByteLetters(t)
+	words := []string{}
+	for _, stem := range []string{"jump", "lift", "pull", "push", "talk", "walk"} {
+		for _, ending := range []string{"", "ed", "er", "ers", "ing", "s"} {
+			words = append(words, stem+ending)
+		}
+	}
+	sort.Strings(words)
+
+	te := Trie{}
+	te.Init()
+	for _, word := range words {
+		te.Insert(word)
+	}
+
+	for _, kind := range []RankSelectKind{RankDirectoryKind, PlainKind, RRRKind} {
+		dawg := FrozenDAWG{}
+		assert.Nil(t, dawg.Init(te.EncodeDAWG(kind)))
+		assert.Less(t, dawg.nodeCount*3, te.GetNodeCount())
+		assert.Equal(t, kind, dawg.directory.Kind())
+		var dict Dictionary = &dawg
+
+		for i, word := range words {
+			index, found := dict.LookupIndex(word)
+			assert.True(t, found, word)
+			assert.Equal(t, uint(i+1), index, word)
+		}
+		for _, word := range []string{"", "jum", "jumpe", "walke", "talkings", "x"} {
+			assert.False(t, dict.Lookup(word), word)
+		}
+
+		suggested := dict.GetSuggestedWords("pu", 100)
+		sort.Strings(suggested)
+		assert.Equal(t, words[12:24], suggested)
+		assert.Equal(t, 0, len(dict.GetSuggestedWords("pa", 100)))
+	}
+}
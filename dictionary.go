@@ -0,0 +1,139 @@
+package bits
+
+import "fmt"
+
+/*
+*
+
+	Dictionary is the query API shared by the frozen encodings of a trie, so
+	callers can switch encodings without code changes. The meaning of the
+	index returned by LookupIndex depends on the encoding. SetFolding
+	applies a FoldingTable to the queries. Every encoding records the
+	normalization form of the keys, and normalizes the words it is queried
+	with to it.
+*/
+type Dictionary interface {
+	Lookup(word string) bool
+	LookupIndex(word string) (index uint, found bool)
+	GetSuggestedWords(word string, limit int) []string
+	SetFolding(table *FoldingTable)
+	GetNormalization() NormalizationForm
+}
+
+/*
+*
+
+	Topology selects how Trie.Freeze stores the shape of the trie.
+*/
+type Topology uint8
+
+const (
+	// Level-order unary degree sequence, as in FrozenTrie.
+	LOUDSTopology Topology = iota
+	// Balanced parentheses, as in FrozenBPTrie.
+	BPTopology
+	// LOUDS with the chains ending in a leaf cut into tails, as in a
+	// FrozenTrie with InitTails.
+	TailTopology
+	// Path-compressed LOUDS, as in FrozenPatriciaTrie.
+	PatriciaTopology
+	// Minimized into a directed acyclic word graph, as in FrozenDAWG.
+	DAWGTopology
+	// LOUDS-dense over the top levels and LOUDS-sparse below, as in
+	// FrozenFastTrie.
+	FastTopology
+)
+
+// the number of levels EncodeDictionary stores in the LOUDS-dense layout
+// of FastTopology
+const fastDenseLevels = 2
+
+// the version of the format written by EncodeDictionary
+const dictionaryVersion = 1
+
+/*
+*
+
+	Encodes the trie with the given topology into a single string, to be
+	read with LoadDictionary. kind selects the RankSelect over the bits of
+	the topology. The string starts with a format version and the topology,
+	followed by the encoding of the topology, like the result of EncodeBP.
+*/
+func (t *Trie) EncodeDictionary(topology Topology, kind RankSelectKind) (string, error) {
+	var encoding string
+	switch topology {
+	case LOUDSTopology:
+		encoding = t.EncodeLOUDS(kind, false)
+	case BPTopology:
+		encoding = t.EncodeBP(kind)
+	case TailTopology:
+		encoding = t.EncodeLOUDS(kind, true)
+	case PatriciaTopology:
+		encoding = t.EncodePatricia(kind)
+	case DAWGTopology:
+		encoding = t.EncodeDAWG(kind)
+	case FastTopology:
+		encoding = t.EncodeFast(fastDenseLevels, kind)
+	default:
+		return "", fmt.Errorf("dictionary: unknown topology %d", topology)
+	}
+	return string([]byte{dictionaryVersion, byte(topology)}) + encoding, nil
+}
+
+/*
+*
+
+	Restores a dictionary from the string returned by Trie.EncodeDictionary.
+	The concrete type of the result follows the topology: *FrozenTrie for
+	LOUDSTopology and TailTopology, *FrozenBPTrie, *FrozenPatriciaTrie,
+	*FrozenDAWG or *FrozenFastTrie.
+*/
+func LoadDictionary(data string) (Dictionary, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("dictionary: header too short")
+	}
+	if data[0] != dictionaryVersion {
+		return nil, fmt.Errorf("dictionary: unknown version %d", data[0])
+	}
+
+	var dict interface {
+		Dictionary
+		Init(data string) error
+	}
+	switch Topology(data[1]) {
+	case LOUDSTopology, TailTopology:
+		ft := &FrozenTrie{}
+		if err := ft.InitFromData(data[2:]); err != nil {
+			return nil, err
+		}
+		return ft, nil
+	case BPTopology:
+		dict = &FrozenBPTrie{}
+	case PatriciaTopology:
+		dict = &FrozenPatriciaTrie{}
+	case DAWGTopology:
+		dict = &FrozenDAWG{}
+	case FastTopology:
+		dict = &FrozenFastTrie{}
+	default:
+		return nil, fmt.Errorf("dictionary: unknown topology %d", data[1])
+	}
+	if err := dict.Init(data[2:]); err != nil {
+		return nil, err
+	}
+	return dict, nil
+}
+
+/*
+*
+
+	Encodes the trie with the given topology and returns it frozen, as
+	LoadDictionary would.
+*/
+func (t *Trie) Freeze(topology Topology) (Dictionary, error) {
+	data, err := t.EncodeDictionary(topology, RankDirectoryKind)
+	if err != nil {
+		return nil, err
+	}
+	return LoadDictionary(data)
+}
@@ -0,0 +1,57 @@
+package bits
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDictionary(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertInAlphabeticalOrder(&te)
+	te.Insert("app")
+	te.Insert("quizzes")
+	ft := freezeTrie(&te)
+
+	topologies := []Topology{LOUDSTopology, BPTopology, TailTopology, PatriciaTopology, DAWGTopology, FastTopology}
+	for _, topology := range topologies {
+		frozen, err := te.Freeze(topology)
+		assert.Nil(t, err)
+		dicts := []Dictionary{frozen}
+		for _, kind := range []RankSelectKind{PlainKind, RRRKind} {
+			data, err := te.EncodeDictionary(topology, kind)
+			assert.Nil(t, err)
+			dict, err := LoadDictionary(data)
+			assert.Nil(t, err)
+			dicts = append(dicts, dict)
+		}
+
+		for _, dict := range dicts {
+			for _, word := range []string{"alphapha", "app", "apple", "hello", "quiz", "quizzes", "", "ap", "quizz", "zebra"} {
+				assert.Equal(t, ft.Lookup(word), dict.Lookup(word), topology, word)
+			}
+			for _, prefix := range []string{"", "a", "qu", "x"} {
+				suggested := dict.GetSuggestedWords(prefix, 20)
+				sort.Strings(suggested)
+				expected := ft.GetSuggestedWords(prefix, 20)
+				sort.Strings(expected)
+				assert.Equal(t, expected, suggested, topology, prefix)
+			}
+		}
+	}
+
+	_, err := te.Freeze(Topology(9))
+	assert.NotNil(t, err)
+	_, err = te.EncodeDictionary(Topology(9), RankDirectoryKind)
+	assert.NotNil(t, err)
+	_, err = LoadDictionary("")
+	assert.NotNil(t, err)
+	data, _ := te.EncodeDictionary(DAWGTopology, RankDirectoryKind)
+	_, err = LoadDictionary("\x09" + data[1:])
+	assert.NotNil(t, err)
+	_, err = LoadDictionary(data[:1] + "\x09" + data[2:])
+	assert.NotNil(t, err)
+}
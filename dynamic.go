@@ -0,0 +1,294 @@
+package bits
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/*
+*
+
+	DynamicTrie keeps a dictionary queryable while it changes. It combines a
+	read-only FrozenTrie with a small in-memory delta of inserted words and
+	tombstones for deleted ones, and every query merges both. Compact folds
+	the delta into a new FrozenTrie in the background.
+
+	A DynamicTrie is safe for concurrent use.
+*/
+type DynamicTrie struct {
+	mu   sync.RWMutex
+	base *FrozenTrie
+	// the changes being folded into a new base by Compact, or nil
+	pending map[string]bool
+	// the changes since, which override pending. true marks an inserted
+	// word, false a tombstone.
+	delta map[string]bool
+
+	// how Compact builds a new base: as the first one was built, with its
+	// rank/select kinds, its tails if it has any, its folding table and
+	// the normalization of its keys.
+	kind       RankSelectKind
+	withTails  bool
+	tailKind   RankSelectKind
+	folding    *FoldingTable
+	normalizer normalizer
+}
+
+/*
+*
+
+	Creates a dynamic trie holding the words of base. The bases built by
+	Compact are encoded like base.
+*/
+func NewDynamicTrie(base *FrozenTrie) *DynamicTrie {
+	d := &DynamicTrie{
+		base:       base,
+		delta:      map[string]bool{},
+		kind:       base.GetDirectory().Kind(),
+		folding:    base.GetFolding(),
+		normalizer: base.normalizer,
+	}
+	if base.tails != nil {
+		d.withTails = true
+		d.tailKind = base.tails.hasTail.Kind()
+	}
+	return d
+}
+
+/*
+*
+
+	Returns the current frozen base.
+*/
+func (d *DynamicTrie) GetBase() *FrozenTrie {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.base
+}
+
+/*
+*
+
+	Adds a word.
+*/
+func (d *DynamicTrie) Insert(word string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.delta[d.normalizer.normalizeWord(word)] = true
+}
+
+/*
+*
+
+	Removes a word. Returns false if it was not there.
+*/
+func (d *DynamicTrie) Delete(word string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	word = d.normalizer.normalizeWord(word)
+	if !d.lookup(word) {
+		return false
+	}
+	d.delta[word] = false
+	return true
+}
+
+/*
+*
+
+	Look-up a word. Returns true if and only if the word exists.
+*/
+func (d *DynamicTrie) Lookup(word string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.lookup(d.normalizer.normalizeWord(word))
+}
+
+func (d *DynamicTrie) lookup(word string) bool {
+	if inserted, ok := d.delta[word]; ok {
+		return inserted
+	}
+	if inserted, ok := d.pending[word]; ok {
+		return inserted
+	}
+	return d.base.Lookup(word)
+}
+
+// Returns the changes of pending and delta together.
+func (d *DynamicTrie) overlay() map[string]bool {
+	result := make(map[string]bool, len(d.pending)+len(d.delta))
+	for word, inserted := range d.pending {
+		result[word] = inserted
+	}
+	for word, inserted := range d.delta {
+		result[word] = inserted
+	}
+	return result
+}
+
+/*
+*
+
+	Given a word, returns array of words, prefix of which is word. Shorter
+	words come first, then words in increasing order, which is the order of
+	FrozenTrie.GetSuggestedWords for a trie built from sorted words. The
+	limit is the same as for FrozenTrie.GetSuggestedWords.
+*/
+func (d *DynamicTrie) GetSuggestedWords(word string, limit int) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	word = d.normalizer.normalizeWord(word)
+	overlay := d.overlay()
+
+	// ask the base for enough words to make up for the tombstones.
+	var result []string
+	for _, suggestion := range d.base.GetSuggestedWords(word, limit+len(overlay)) {
+		if _, ok := overlay[suggestion]; !ok {
+			result = append(result, suggestion)
+		}
+	}
+	for suggestion, inserted := range overlay {
+		if inserted && strings.HasPrefix(suggestion, word) {
+			result = append(result, suggestion)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i]) != len(result[j]) {
+			return len(result[i]) < len(result[j])
+		}
+		return result[i] < result[j]
+	})
+	if len(result) > limit+1 {
+		result = result[:limit+1]
+	}
+	return result
+}
+
+/*
+*
+
+	Calls fn with every word in increasing order, until fn returns false.
+*/
+func (d *DynamicTrie) Iterate(fn func(word string) bool) {
+	d.mu.RLock()
+	base, overlay := d.base, d.overlay()
+	d.mu.RUnlock()
+	merge(base, overlay, fn)
+}
+
+// Calls fn with the words of base with the changes of overlay applied, in
+// increasing order, until fn returns false.
+func merge(base *FrozenTrie, overlay map[string]bool, fn func(word string) bool) {
+	var inserted []string
+	for word, ok := range overlay {
+		if ok {
+			inserted = append(inserted, word)
+		}
+	}
+	sort.Strings(inserted)
+
+	stopped := false
+	base.Walk(func(key string, node FrozenTrieNode) WalkAction {
+		if !node.IsFinal() {
+			return Continue
+		}
+		for len(inserted) > 0 && inserted[0] < key {
+			if !fn(inserted[0]) {
+				stopped = true
+				return Stop
+			}
+			inserted = inserted[1:]
+		}
+		if _, ok := overlay[key]; ok {
+			// inserted words are emitted from the overlay.
+			return Continue
+		}
+		if !fn(key) {
+			stopped = true
+			return Stop
+		}
+		return Continue
+	})
+	for _, word := range inserted {
+		if stopped || !fn(word) {
+			return
+		}
+	}
+}
+
+/*
+*
+
+	Builds a new FrozenTrie from the base and the changes made so far, in
+	the background, then swaps it in as the base. Queries and changes go on
+	meanwhile; changes made after the call stay in the delta. The returned
+	channel receives the result once done, nil on success. On an error the
+	base is kept, and the changes stay in the delta.
+*/
+func (d *DynamicTrie) Compact() <-chan error {
+	done := make(chan error, 1)
+
+	d.mu.Lock()
+	if d.pending != nil {
+		d.mu.Unlock()
+		done <- fmt.Errorf("dynamic: compaction already running")
+		return done
+	}
+	d.pending, d.delta = d.delta, map[string]bool{}
+	base, pending := d.base, d.pending
+	d.mu.Unlock()
+
+	go func() {
+		compacted, err := d.rebuild(base, pending)
+
+		d.mu.Lock()
+		if err == nil {
+			d.base = compacted
+		} else {
+			for word, inserted := range d.pending {
+				if _, ok := d.delta[word]; !ok {
+					d.delta[word] = inserted
+				}
+			}
+		}
+		d.pending = nil
+		d.mu.Unlock()
+		done <- err
+	}()
+	return done
+}
+
+// Builds a frozen trie of the words of base with the changes of pending
+// applied, encoded like the first base.
+func (d *DynamicTrie) rebuild(base *FrozenTrie, pending map[string]bool) (*FrozenTrie, error) {
+	te := Trie{}
+	te.Init()
+	te.normalizer = d.normalizer
+	merge(base, pending, func(word string) bool {
+		te.Insert(word)
+		return true
+	})
+
+	compacted := &FrozenTrie{}
+	if d.withTails {
+		encoding, tailData, nodeCount, _ := te.EncodeWithTails(d.tailKind)
+		if err := compacted.InitWithRankSelect(encoding,
+			CreateRankSelect(d.kind, encoding, nodeCount*2+1), nodeCount); err != nil {
+			return nil, err
+		}
+		if err := compacted.InitTails(tailData); err != nil {
+			return nil, err
+		}
+	} else {
+		teData, _ := te.Encode()
+		if err := compacted.InitWithRankSelect(teData,
+			CreateRankSelect(d.kind, teData, te.GetNodeCount()*2+1), te.GetNodeCount()); err != nil {
+			return nil, err
+		}
+	}
+	compacted.SetFolding(d.folding)
+	return compacted, nil
+}
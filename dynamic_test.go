@@ -0,0 +1,98 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dynamicWords(d *DynamicTrie) []string {
+	var words []string
+	d.Iterate(func(word string) bool {
+		words = append(words, word)
+		return true
+	})
+	return words
+}
+
+func TestDynamicTrie(t *testing.T) {
+	d := NewDynamicTrie(freezeWords(t, "alphapha", "apple", "hello", "jello", "lamp"))
+
+	d.Insert("apricot")
+	d.Insert("zebra")
+	d.Insert("apple")
+	assert.True(t, d.Delete("hello"))
+	assert.False(t, d.Delete("hello"))
+	assert.False(t, d.Delete("kiwi"))
+
+	assert.True(t, d.Lookup("apricot"))
+	assert.True(t, d.Lookup("apple"))
+	assert.True(t, d.Lookup("jello"))
+	assert.False(t, d.Lookup("hello"))
+	expected := []string{"alphapha", "apple", "apricot", "jello", "lamp", "zebra"}
+	assert.Equal(t, expected, dynamicWords(d))
+	assert.Equal(t, []string{"apple", "apricot", "alphapha"}, d.GetSuggestedWords("a", 5))
+	assert.Equal(t, []string{"apple", "apricot"}, d.GetSuggestedWords("a", 1))
+	assert.Empty(t, d.GetSuggestedWords("h", 5))
+
+	var first []string
+	d.Iterate(func(word string) bool {
+		first = append(first, word)
+		return len(first) < 3
+	})
+	assert.Equal(t, expected[:3], first)
+
+	done := d.Compact()
+	// changes during the compaction stay in the delta
+	d.Insert("hello")
+	d.Delete("lamp")
+	assert.Nil(t, <-done)
+
+	base := d.GetBase()
+	assert.True(t, base.Lookup("zebra"))
+	assert.True(t, base.Lookup("lamp"))
+	assert.False(t, base.Lookup("hello"))
+	assert.Equal(t, []string{"alphapha", "apple", "apricot", "hello", "jello", "zebra"}, dynamicWords(d))
+
+	assert.Nil(t, <-d.Compact())
+	assert.Equal(t, dynamicWords(d), dynamicWords(NewDynamicTrie(d.GetBase())))
+}
+
+func TestDynamicTrieCompactOptions(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	assert.Nil(t, te.SetNormalization(NFC))
+	te.Insert("dhamma")
+	te.Insert("nibbāna")
+	encoding, tailData, nodeCount, _ := te.EncodeWithTails(PlainKind)
+	base := &FrozenTrie{}
+	assert.Nil(t, base.InitWithRankSelect(encoding, CreateRankSelect(RRRKind, encoding, nodeCount*2+1), nodeCount))
+	assert.Nil(t, base.InitTails(tailData))
+	base.SetFolding(NewFoldingTable(PaliFolding))
+
+	d := NewDynamicTrie(base)
+	d.Insert("pīti")
+	assert.True(t, d.Lookup("pīti"))
+	assert.Nil(t, <-d.Compact())
+
+	compacted := d.GetBase()
+	assert.Equal(t, RRRKind, compacted.GetDirectory().Kind())
+	if assert.NotNil(t, compacted.tails) {
+		assert.Equal(t, PlainKind, compacted.tails.hasTail.Kind())
+	}
+	assert.Equal(t, NFC, compacted.GetNormalization())
+	assert.Equal(t, base.GetFolding(), compacted.GetFolding())
+	assert.True(t, compacted.Lookup("pīti"))
+	assert.True(t, compacted.Lookup("nibbana"))
+
+	// a failed compaction keeps the base and the changes
+	d.Insert("sutta")
+	d.normalizer.normalization = NFKD + 1
+	assert.NotNil(t, <-d.Compact())
+	d.normalizer.normalization = NFC
+	assert.Equal(t, compacted, d.GetBase())
+	assert.True(t, d.Lookup("sutta"))
+	assert.Nil(t, <-d.Compact())
+	assert.True(t, d.GetBase().Lookup("sutta"))
+}
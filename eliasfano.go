@@ -0,0 +1,188 @@
+package bits
+
+import "fmt"
+
+/*
+*
+
+	EliasFano stores a non-decreasing sequence of integers in close to the
+	information theoretic minimum of bits, while still allowing random access.
+
+	Every value is split into a high and a low part. The low parts take
+	lowBits bits each and are stored verbatim. The high parts are stored in
+	unary in the upper bit string: value i sets the bit at position
+	high(i) + i, so the number of 0 bits before it equals its high part. A
+	RankDirectory over the upper bits finds the i'th 1 bit, and so the i'th
+	value, without a scan.
+*/
+type EliasFano struct {
+	upper     RankDirectory
+	lower     BitString
+	length    uint
+	lowBits   uint
+	upperBits uint
+}
+
+/*
+*
+
+	Builds the sequence from the given values, which must be in
+	non-decreasing order.
+*/
+func CreateEliasFano(values []uint64) (EliasFano, error) {
+	length := uint(len(values))
+	for i := 1; i < len(values); i++ {
+		if values[i] < values[i-1] {
+			return EliasFano{}, fmt.Errorf("eliasfano: value %d at position %d is smaller than its predecessor", values[i], i)
+		}
+	}
+
+	// lowBits is about log2(largest value / length), which balances the
+	// size of the lower bits against the length of the unary upper bits.
+	var lowBits uint
+	if length > 0 {
+		universe := values[length-1]/uint64(length) + 1
+		for lowBits < 63 && (uint64(1)<<(lowBits+1)) <= universe {
+			lowBits++
+		}
+	}
+
+	upper := BitWriter{}
+	lower := BitWriter{}
+	var high uint64
+	for _, value := range values {
+		for ; high < value>>lowBits; high++ {
+			upper.Write(0, 1)
+		}
+		upper.Write(1, 1)
+		lower.Write64(value&(uint64(1)<<lowBits-1), lowBits)
+	}
+	// a closing 0 bit, so every high part up to the last one has a 0 bit
+	// ending its bucket.
+	upper.Write(0, 1)
+
+	ef := EliasFano{
+		upper:     CreateRankDirectory(upper.GetData(), upper.Len(), L1, L2),
+		length:    length,
+		lowBits:   lowBits,
+		upperBits: upper.Len(),
+	}
+	ef.lower.Init(lower.GetData())
+	return ef, nil
+}
+
+/*
+*
+
+	Restores a sequence from the string returned by GetData.
+*/
+func (ef *EliasFano) Init(data string) error {
+	sections, err := splitSections(data, 4)
+	if err != nil {
+		return err
+	}
+
+	if len(sections[0]) < 9 {
+		return fmt.Errorf("eliasfano: header too short")
+	}
+	header := BitString{}
+	header.Init(sections[0])
+	ef.length = header.Get(0, 32)
+	ef.lowBits = header.Get(32, 8)
+	ef.upperBits = header.Get(40, 32)
+
+	if ef.lowBits > 64 || ef.upperBits < ef.length {
+		return fmt.Errorf("eliasfano: invalid header")
+	}
+	if uint(len(sections[1]))*8 < ef.upperBits ||
+		uint(len(sections[2]))*8 < ef.length*ef.lowBits ||
+		uint(len(sections[3])) < rankDirectorySize(ef.upperBits, L1, L2) {
+		return fmt.Errorf("eliasfano: data too short")
+	}
+
+	ef.upper.Init(sections[3], sections[1], ef.upperBits, L1, L2)
+	ef.lower.Init(sections[2])
+	if ef.upperBits > 0 && ef.upper.Rank(1, ef.upperBits-1) != ef.length {
+		return fmt.Errorf("eliasfano: upper bits do not hold %d values", ef.length)
+	}
+	return nil
+}
+
+/*
+*
+
+	Returns the encoded sequence, including its rank directory.
+*/
+func (ef *EliasFano) GetData() string {
+	header := BitWriter{}
+	header.Write(ef.length, 32)
+	header.Write(ef.lowBits, 8)
+	header.Write(ef.upperBits, 32)
+
+	return joinSections(header.GetData(), ef.upper.data.GetData(),
+		ef.lower.GetData(), ef.upper.GetData())
+}
+
+/*
+*
+
+	Returns the number of values in the sequence.
+*/
+func (ef *EliasFano) Len() uint {
+	return ef.length
+}
+
+/*
+*
+
+	Returns the i'th value (0-based) of the sequence.
+*/
+func (ef *EliasFano) Get(i uint) uint64 {
+	high := uint64(ef.upper.Select(1, i+1) - i)
+	return high<<ef.lowBits | ef.getLow(i)
+}
+
+func (ef *EliasFano) getLow(i uint) uint64 {
+	return ef.lower.Get64(i*ef.lowBits, ef.lowBits)
+}
+
+/*
+*
+
+	Finds the first value that is greater than or equal to x. Returns its
+	position and the value itself; found is false if every value is smaller
+	than x.
+*/
+func (ef *EliasFano) NextGEQ(x uint64) (i uint, value uint64, found bool) {
+	if ef.length == 0 {
+		return 0, 0, false
+	}
+
+	high := x >> ef.lowBits
+	// the number of 0 bits in the upper bits is one more than the largest
+	// high part.
+	if high >= uint64(ef.upperBits-ef.length) {
+		return 0, 0, false
+	}
+
+	// skip to the bucket of values sharing the high part of x.
+	var pos uint
+	if high > 0 {
+		pos = ef.upper.Select(0, uint(high)) + 1
+		i = pos - uint(high)
+	}
+
+	for ; pos < ef.upperBits && i < ef.length; pos++ {
+		if ef.upper.data.Get(pos, 1) == 0 {
+			high++
+			continue
+		}
+		value = high<<ef.lowBits | ef.getLow(i)
+		if value >= x {
+			return i, value, true
+		}
+		i++
+	}
+
+	return 0, 0, false
+}
@@ -0,0 +1,57 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEliasFano(t *testing.T) {
+	values := []uint64{0, 0, 3, 7, 7, 8, 100, 101, 5000, 5000, 1 << 40}
+	for i := uint64(0); i < 3000; i += 3 {
+		values = append(values, 1<<40+i*i)
+	}
+
+	built, err := CreateEliasFano(values)
+	assert.Nil(t, err)
+
+	ef := EliasFano{}
+	assert.Nil(t, ef.Init(built.GetData()))
+	assert.Equal(t, uint(len(values)), ef.Len())
+	for i, value := range values {
+		require.Equal(t, value, ef.Get(uint(i)), i)
+	}
+
+	i, value, found := ef.NextGEQ(8)
+	assert.True(t, found)
+	assert.Equal(t, uint(5), i)
+	assert.Equal(t, uint64(8), value)
+
+	i, value, found = ef.NextGEQ(102)
+	assert.True(t, found)
+	assert.Equal(t, uint(8), i)
+	assert.Equal(t, uint64(5000), value)
+
+	_, value, found = ef.NextGEQ(1<<40 + 1)
+	assert.True(t, found)
+	assert.Equal(t, uint64(1<<40+9), value)
+
+	_, _, found = ef.NextGEQ(values[len(values)-1] + 1)
+	assert.False(t, found)
+
+	empty, err := CreateEliasFano(nil)
+	assert.Nil(t, err)
+	_, _, found = empty.NextGEQ(0)
+	assert.False(t, found)
+
+	_, err = CreateEliasFano([]uint64{2, 1})
+	assert.NotNil(t, err)
+
+	wide, err := CreateEliasFano([]uint64{1<<62 + 5, 1<<63 + 1})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1<<63+1), wide.Get(1))
+
+	data := built.GetData()
+	assert.NotNil(t, ef.Init(data[:len(data)-1]))
+}
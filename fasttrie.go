@@ -0,0 +1,462 @@
+package bits
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+*
+
+	FrozenFastTrie stores the trie in the hybrid layout of the Fast Succinct
+	Trie from SuRF. The top levels, which every lookup passes through and
+	which hold few nodes, use the LOUDS-dense layout: each node has a 256 bit
+	bitmap of the letters of its children and a 256 bit bitmap telling which
+	of those children have children of their own, so finding a child is a
+	single bit test and rank. The lower levels use the compact LOUDS-sparse
+	layout: one byte per edge plus a bit marking the edges that lead to a
+	node with children and a bit marking the first edge of each node.
+
+	Only the nodes with children are numbered, in level order. The k'th edge
+	that leads to a node with children, counting the dense edges first,
+	leads to node k.
+
+	Children are kept in increasing order of their letters. Edges are
+	numbered in level order, dense edges first, and the edge leading to a
+	node is that node's index: the same level-order index as in a FrozenTrie
+	built from sorted words.
+
+	This is where the two diverge for a trie built from unsorted words. A
+	FrozenTrie keeps the children in the order they were inserted, so its
+	indices and the order of its suggestions follow the insertion order,
+	while those of a FrozenFastTrie are always the ones of the sorted build.
+*/
+type FrozenFastTrie struct {
+	// the bitmaps, and rank/select indexes over them
+	bits      [4]BitString
+	directory [4]RankSelect
+
+	sparseLabels  BitString
+	finals        BitString
+	denseNodes    uint
+	denseEdges    uint
+	denseChildren uint
+	sparseNodes   uint
+	sparseEdges   uint
+	rootFinal     bool
+	foldable
+	normalizer
+}
+
+// the bitmaps of a FrozenFastTrie
+const (
+	fastDenseLabels = iota
+	fastDenseHasChild
+	fastSparseHasChild
+	fastSparseLOUDS
+)
+
+/*
+*
+
+	Encode the trie with the top denseLevels levels in the LOUDS-dense
+	layout and the rest in the LOUDS-sparse layout. The bitmaps are indexed
+	by RankSelects of the given kind. The header records the normalization
+	of the keys. Returns a string to be read with FrozenFastTrie.Init.
+*/
+func (t *Trie) EncodeFast(denseLevels uint, kind RankSelectKind) string {
+	type item struct {
+		node  *TrieNode
+		depth uint
+	}
+
+	denseLabels := BitWriter{}
+	denseHasChild := BitWriter{}
+	sparseLabels := BitWriter{}
+	sparseHasChild := BitWriter{}
+	sparseLOUDS := BitWriter{}
+	finals := BitWriter{}
+	var denseNodes uint = 0
+
+	writeBit := func(bw *BitWriter, bit bool) {
+		if bit {
+			bw.Write(1, 1)
+		} else {
+			bw.Write(0, 1)
+		}
+	}
+
+	var queue []item
+	if len(t.root.children) > 0 {
+		queue = append(queue, item{t.root, 0})
+	}
+	for len(queue) > 0 {
+		now := queue[0]
+		queue = queue[1:]
+
+		children := make([]*TrieNode, len(now.node.children))
+		copy(children, now.node.children)
+		sort.Slice(children, func(i, j int) bool {
+			return children[i].letter < children[j].letter
+		})
+
+		if now.depth < denseLevels {
+			denseNodes++
+			var labels, hasChild [256]bool
+			for _, child := range children {
+				labels[child.letter] = true
+				hasChild[child.letter] = len(child.children) > 0
+			}
+			for c := 0; c < 256; c++ {
+				writeBit(&denseLabels, labels[c])
+				writeBit(&denseHasChild, hasChild[c])
+			}
+		} else {
+			for i, child := range children {
+				sparseLabels.Write(uint(child.letter), 8)
+				writeBit(&sparseHasChild, len(child.children) > 0)
+				writeBit(&sparseLOUDS, i == 0)
+			}
+		}
+
+		for _, child := range children {
+			writeBit(&finals, child.final)
+			if len(child.children) > 0 {
+				queue = append(queue, item{child, now.depth + 1})
+			}
+		}
+	}
+
+	header := BitWriter{}
+	header.Write(denseNodes, 32)
+	header.Write(sparseLOUDS.Len(), 32)
+	writeBit(&header, t.root.final)
+	t.writeHeader(&header)
+
+	sections := []string{header.GetData(), sparseLabels.GetData(), finals.GetData()}
+	for _, bw := range []*BitWriter{&denseLabels, &denseHasChild, &sparseHasChild, &sparseLOUDS} {
+		rs := CreateRankSelect(kind, bw.GetData(), bw.Len())
+		sections = append(sections, bw.GetData(), EncodeRankSelect(rs))
+	}
+	return joinSections(sections...)
+}
+
+/*
+*
+
+	Initializes the trie from the string returned by Trie.EncodeFast.
+*/
+func (f *FrozenFastTrie) Init(data string) error {
+	sections, err := splitSections(data, 11)
+	if err != nil {
+		return err
+	}
+	if len(sections[0]) < 11 {
+		return fmt.Errorf("fasttrie: header too short")
+	}
+	header := BitString{}
+	header.Init(sections[0])
+	f.denseNodes = header.Get(0, 32)
+	f.sparseEdges = header.Get(32, 32)
+	f.rootFinal = header.Get(64, 1) == 1
+	if err := f.readHeader(&header, 65); err != nil {
+		return err
+	}
+	f.sparseLabels.Init(sections[1])
+	f.finals.Init(sections[2])
+
+	sizes := [4]uint{f.denseNodes * 256, f.denseNodes * 256, f.sparseEdges, f.sparseEdges}
+	for i := range f.bits {
+		f.bits[i].Init(sections[3+2*i])
+		f.directory[i], err = DecodeRankSelect(sections[4+2*i], sections[3+2*i], sizes[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	f.denseEdges, f.denseChildren, f.sparseNodes = 0, 0, 0
+	if f.denseNodes > 0 {
+		f.denseEdges = f.directory[fastDenseLabels].Rank1(f.denseNodes*256 - 1)
+		f.denseChildren = f.directory[fastDenseHasChild].Rank1(f.denseNodes*256 - 1)
+	}
+	if f.sparseEdges > 0 {
+		f.sparseNodes = f.directory[fastSparseLOUDS].Rank1(f.sparseEdges - 1)
+	}
+	return nil
+}
+
+// Finds the edge with the given letter leaving node. Returns its level-order
+// number, whether it leads to a node with children, and that node.
+func (f *FrozenFastTrie) child(node uint, letter byte) (edge uint, hasChild bool, next uint, found bool) {
+	if node < f.denseNodes {
+		p := node*256 + uint(letter)
+		if f.bits[fastDenseLabels].Get(p, 1) == 0 {
+			return 0, false, 0, false
+		}
+		edge, hasChild, next = f.denseEdge(p)
+		return edge, hasChild, next, true
+	}
+
+	first, end := f.sparseRange(node)
+	for q := first; q < end; q++ {
+		if byte(f.sparseLabels.Get(q*8, 8)) == letter {
+			edge, hasChild, next = f.sparseEdge(q)
+			return edge, hasChild, next, true
+		}
+	}
+	return 0, false, 0, false
+}
+
+// the edge at position p of the dense bitmaps
+func (f *FrozenFastTrie) denseEdge(p uint) (edge uint, hasChild bool, next uint) {
+	edge = f.directory[fastDenseLabels].Rank1(p) - 1
+	if f.bits[fastDenseHasChild].Get(p, 1) == 0 {
+		return edge, false, 0
+	}
+	return edge, true, f.directory[fastDenseHasChild].Rank1(p)
+}
+
+// the edge at position q of the sparse levels
+func (f *FrozenFastTrie) sparseEdge(q uint) (edge uint, hasChild bool, next uint) {
+	edge = f.denseEdges + q
+	if f.bits[fastSparseHasChild].Get(q, 1) == 0 {
+		return edge, false, 0
+	}
+	return edge, true, f.denseChildren + f.directory[fastSparseHasChild].Rank1(q)
+}
+
+// the range of sparse edges leaving a node stored in the sparse levels
+func (f *FrozenFastTrie) sparseRange(node uint) (first, end uint) {
+	local := node - f.denseNodes
+	first = f.directory[fastSparseLOUDS].Select1(local + 1)
+	if local+1 < f.sparseNodes {
+		end = f.directory[fastSparseLOUDS].Select1(local + 2)
+	} else {
+		end = f.sparseEdges
+	}
+	return first, end
+}
+
+// calls fn with the letter, edge number and child of each edge leaving node
+func (f *FrozenFastTrie) edges(node uint, fn func(letter byte, edge uint, hasChild bool, next uint)) {
+	if node < f.denseNodes {
+		for c := uint(0); c < 256; c++ {
+			p := node*256 + c
+			if f.bits[fastDenseLabels].Get(p, 1) == 1 {
+				edge, hasChild, next := f.denseEdge(p)
+				fn(byte(c), edge, hasChild, next)
+			}
+		}
+		return
+	}
+
+	first, end := f.sparseRange(node)
+	for q := first; q < end; q++ {
+		edge, hasChild, next := f.sparseEdge(q)
+		fn(byte(f.sparseLabels.Get(q*8, 8)), edge, hasChild, next)
+	}
+}
+
+/*
+*
+
+	A position in the trie: the root, or the edge of the last letter
+	followed and the node it leads to if it has children.
+*/
+type fastPosition struct {
+	root     bool
+	edge     uint
+	hasChild bool
+	node     uint
+}
+
+// Returns the position reached from p by the letter.
+func (f *FrozenFastTrie) step(p fastPosition, letter byte) (fastPosition, bool) {
+	if !p.hasChild {
+		return p, false
+	}
+	edge, hasChild, node, found := f.child(p.node, letter)
+	if !found {
+		return p, false
+	}
+	return fastPosition{false, edge, hasChild, node}, true
+}
+
+// Returns whether the letters leading to the position are a word, and its
+// index as returned by LookupIndex.
+func (f *FrozenFastTrie) final(p fastPosition) (final bool, index uint) {
+	if p.root {
+		return f.rootFinal, 0
+	}
+	return f.finals.Get(p.edge, 1) == 1, p.edge + 1
+}
+
+// A position at the end of a spelling of the word passed to descend.
+type fastDescent struct {
+	position fastPosition
+	spelling string
+}
+
+/*
+*
+
+	Follows word, once normalized like the keys, from the root, or with a
+	folding table, every spelling that folds like it, the exact one first.
+*/
+func (f *FrozenFastTrie) descend(word string) []fastDescent {
+	var result []fastDescent
+	word = f.normalizeWord(word)
+	positions := []fastPosition{{root: true, hasChild: f.denseNodes > 0 || f.sparseEdges > 0}}
+	f.folding.expand(word, 0, func(from int, letter byte) (int, bool) {
+		next, found := f.step(positions[from], letter)
+		if !found {
+			return 0, false
+		}
+		positions = append(positions, next)
+		return len(positions) - 1, true
+	}, func(at int, spelling string, covered int) {
+		if covered == len(word) {
+			result = append(result, fastDescent{positions[at], spelling})
+		}
+	})
+	return result
+}
+
+/*
+*
+
+	Look-up a word in the trie. Returns true if and only if the word exists
+	in the trie.
+*/
+func (f *FrozenFastTrie) Lookup(word string) bool {
+	_, found := f.LookupIndex(word)
+	return found
+}
+
+/*
+*
+
+	Returns the level-order index of the node of the word, as described for
+	FrozenFastTrie.
+*/
+func (f *FrozenFastTrie) LookupIndex(word string) (index uint, found bool) {
+	for _, match := range f.descend(word) {
+		if final, index := f.final(match.position); final {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+/*
+*
+
+	Calls fn with every word starting with prefix and its index, as returned
+	by LookupIndex, in increasing order of the words. With a folding table,
+	the words under each spelling of prefix come in turn, the exact
+	spelling first. Stops early if fn returns false.
+*/
+func (f *FrozenFastTrie) Iterate(prefix string, fn func(word string, index uint) bool) {
+	for _, match := range f.descend(prefix) {
+		p := match.position
+		if final, index := f.final(p); final && !fn(match.spelling, index) {
+			return
+		}
+		if p.hasChild && !f.iterate(p.node, []byte(match.spelling), fn) {
+			return
+		}
+	}
+}
+
+func (f *FrozenFastTrie) iterate(node uint, word []byte, fn func(string, uint) bool) bool {
+	more := true
+	f.edges(node, func(letter byte, edge uint, hasChild bool, next uint) {
+		if !more {
+			return
+		}
+		key := append(word, letter)
+		if f.finals.Get(edge, 1) == 1 && !fn(string(key), edge+1) {
+			more = false
+		} else if hasChild {
+			more = f.iterate(next, key, fn)
+		}
+	})
+	return more
+}
+
+/*
+*
+
+	Returns the greatest word in the trie, or "" if it is empty.
+*/
+func (f *FrozenFastTrie) GetLastLexographicKey() string {
+	var last string
+	f.Iterate("", func(word string, _ uint) bool {
+		last = word
+		return true
+	})
+	return last
+}
+
+/*
+*
+
+	Given a word, returns array of words, prefix of which is word. See
+	FrozenTrie.GetSuggestedWords.
+*/
+func (f *FrozenFastTrie) GetSuggestedWords(word string, limit int) []string {
+	var result []string
+	for _, match := range f.descend(word) {
+		if len(result) > limit {
+			break
+		}
+		result = append(result, f.traverseSubTrie(match.position, match.spelling, limit-len(result))...)
+	}
+	return result
+}
+
+func (f *FrozenFastTrie) traverseSubTrie(p fastPosition, word string, limit int) []string {
+	var result []string
+
+	if final, _ := f.final(p); final {
+		result = append(result, word)
+		if len(result) > limit {
+			return result
+		}
+	}
+	if !p.hasChild {
+		return result
+	}
+
+	// traverse in level order. A child's word is reported when its parent
+	// is visited, which gives the same order as visiting the child itself.
+	level := []uint{p.node}
+	prefixLevel := []string{word}
+	for len(level) > 0 {
+		nodeNow := level[0]
+		level = level[1:]
+		prefixNow := prefixLevel[0]
+		prefixLevel = prefixLevel[1:]
+
+		done := false
+		f.edges(nodeNow, func(letter byte, edge uint, hasChild bool, next uint) {
+			if done {
+				return
+			}
+			prefix := prefixNow + string([]byte{letter})
+			if f.finals.Get(edge, 1) == 1 {
+				result = append(result, prefix)
+				done = len(result) > limit
+			}
+			if hasChild {
+				level = append(level, next)
+				prefixLevel = append(prefixLevel, prefix)
+			}
+		})
+		if done {
+			return result
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,85 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFastTrie(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertInAlphabeticalOrder(&te)
+	te.Insert("quizz")
+	te.Insert("quizzes")
+	te.Insert("z")
+
+	ft := freezeTrie(&te)
+
+	words := []string{"alphapha", "apple", "hello", "jello", "lamp", "orange", "quiz", "quizz", "quizzes", "z"}
+	var dicts []Dictionary
+	for _, denseLevels := range []uint{0, 1, 3, 100} {
+		fft := FrozenFastTrie{}
+		assert.Nil(t, fft.Init(te.EncodeFast(denseLevels, RankDirectoryKind)))
+		dicts = append(dicts, &fft)
+	}
+	for _, kind := range []RankSelectKind{PlainKind, RRRKind} {
+		fft := FrozenFastTrie{}
+		assert.Nil(t, fft.Init(te.EncodeFast(2, kind)))
+		assert.Equal(t, kind, fft.directory[fastSparseLOUDS].Kind())
+		dicts = append(dicts, &fft)
+	}
+
+	for _, dict := range dicts {
+		for _, word := range words {
+			index, found := dict.LookupIndex(word)
+			assert.True(t, found, word)
+			expected, _ := ft.LookupIndex(word)
+			assert.Equal(t, expected, index, word)
+		}
+		for _, word := range []string{"", "a", "appl", "applea", "quizze", "zz", "b"} {
+			assert.False(t, dict.Lookup(word), word)
+		}
+		for _, prefix := range []string{"", "a", "qu", "quizz", "z", "x"} {
+			assert.Equal(t, ft.GetSuggestedWords(prefix, 20), dict.GetSuggestedWords(prefix, 20), prefix)
+		}
+		assert.Equal(t, ft.GetSuggestedWords("", 3), dict.GetSuggestedWords("", 3))
+
+		fft := dict.(*FrozenFastTrie)
+		var iterated []string
+		fft.Iterate("", func(word string, index uint) bool {
+			expected, _ := fft.LookupIndex(word)
+			assert.Equal(t, expected, index, word)
+			iterated = append(iterated, word)
+			return true
+		})
+		assert.Equal(t, words, iterated)
+		assert.Equal(t, "z", fft.GetLastLexographicKey())
+	}
+
+	// built from unsorted words, the fast trie numbers the nodes like a
+	// FrozenTrie of the sorted words, not like one of the words as inserted.
+	unsorted := Trie{}
+	unsorted.Init()
+	insertNotInAlphabeticalOrder(&unsorted)
+	fft := FrozenFastTrie{}
+	assert.Nil(t, fft.Init(unsorted.EncodeFast(1, RankDirectoryKind)))
+	asInserted := freezeTrie(&unsorted)
+	hello, _ := fft.LookupIndex("hello")
+	expected, _ := asInserted.LookupIndex("hello")
+	assert.NotEqual(t, expected, hello)
+
+	sorted := Trie{}
+	sorted.Init()
+	insertInAlphabeticalOrder(&sorted)
+	expected, _ = freezeTrie(&sorted).LookupIndex("hello")
+	assert.Equal(t, expected, hello)
+
+	empty := Trie{}
+	empty.Init()
+	fft = FrozenFastTrie{}
+	assert.Nil(t, fft.Init(empty.EncodeFast(2, RankDirectoryKind)))
+	assert.False(t, fft.Lookup("a"))
+	assert.False(t, fft.Lookup(""))
+}
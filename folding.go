@@ -0,0 +1,172 @@
+package bits
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+*
+
+	The default folding of the Pali alphabet onto plain ASCII letters.
+*/
+var PaliFolding = map[rune]rune{
+	'ā': 'a', 'ī': 'i', 'ū': 'u',
+	'ṁ': 'm', 'ṃ': 'm', 'ŋ': 'm',
+	'ṅ': 'n', 'ñ': 'n', 'ṇ': 'n',
+	'ṭ': 't', 'ḍ': 'd', 'ḷ': 'l',
+}
+
+/*
+*
+
+	FoldingTable maps characters, typically with diacritics, onto the
+	characters users type for them. The trie keeps the original spellings;
+	the table is applied at query time only, once set with SetFolding.
+*/
+type FoldingTable struct {
+	fold map[rune]rune
+	// the characters folding to each character, other than itself, in
+	// increasing order
+	unfold map[rune][]rune
+}
+
+/*
+*
+
+	Creates a folding table. Pass PaliFolding for the Pali alphabet.
+*/
+func NewFoldingTable(fold map[rune]rune) *FoldingTable {
+	table := &FoldingTable{fold: map[rune]rune{}, unfold: map[rune][]rune{}}
+	for from, to := range fold {
+		table.fold[from] = to
+		if from != to {
+			table.unfold[to] = append(table.unfold[to], from)
+		}
+	}
+	for _, froms := range table.unfold {
+		sort.Slice(froms, func(i, j int) bool { return froms[i] < froms[j] })
+	}
+	return table
+}
+
+/*
+*
+
+	Returns the string with every character folded. A nil table leaves it
+	as it is.
+*/
+func (ft *FoldingTable) Fold(s string) string {
+	return strings.Map(ft.foldRune, s)
+}
+
+func (ft *FoldingTable) foldRune(r rune) rune {
+	if ft != nil {
+		if to, ok := ft.fold[r]; ok {
+			return to
+		}
+	}
+	return r
+}
+
+// Returns the UTF-8 encodings of every character that folds like r, r
+// itself first.
+func (ft *FoldingTable) variants(r rune) []string {
+	to := ft.foldRune(r)
+	result := []string{string(r)}
+	if to != r {
+		result = append(result, string(to))
+	}
+	for _, from := range ft.unfold[to] {
+		if from != r {
+			result = append(result, string(from))
+		}
+	}
+	return result
+}
+
+/*
+*
+
+	Follows the spellings that fold like text through an encoding, one
+	letter at a time, trying the spelling of text itself first. Positions in
+	the encoding are numbered by the caller, start being the root: step
+	returns the position reached by a letter from another, or false if the
+	encoding has no such letter there. visit is called at each character
+	boundary of text with the position reached, the letters followed and the
+	number of bytes of text they stand for. Without a table only text itself
+	is followed, and visit is called after every byte.
+*/
+func (ft *FoldingTable) expand(text string, start int, step func(from int, letter byte) (int, bool), visit func(at int, spelling string, covered int)) {
+	if ft == nil {
+		at := start
+		visit(at, "", 0)
+		for i := 0; i < len(text); i++ {
+			next, found := step(at, text[i])
+			if !found {
+				return
+			}
+			at = next
+			visit(at, text[:i+1], i+1)
+		}
+		return
+	}
+
+	var follow func(at int, spelling string, covered int)
+	follow = func(at int, spelling string, covered int) {
+		visit(at, spelling, covered)
+		if covered == len(text) {
+			return
+		}
+		r, size := utf8.DecodeRuneInString(text[covered:])
+		variants := []string{text[covered : covered+size]}
+		if r != utf8.RuneError || size > 1 {
+			variants = ft.variants(r)
+		}
+		for _, variant := range variants {
+			next, found := at, true
+			for i := 0; i < len(variant) && found; i++ {
+				next, found = step(next, variant[i])
+			}
+			if found {
+				follow(next, spelling+variant, covered+size)
+			}
+		}
+	}
+	follow(start, "", 0)
+}
+
+/*
+*
+
+	The folding table of a frozen encoding.
+*/
+type foldable struct {
+	folding *FoldingTable
+}
+
+/*
+*
+
+	Makes the queries match the words whose characters fold to the same
+	string as the query, and return them in their original spellings. An
+	exact spelling is preferred where a single word is returned. Pass nil to
+	compare the letters as they are. The table is not part of the encoding;
+	set it again after loading one. Characters are folded one code point at
+	a time, so a table of precomposed characters like PaliFolding needs keys
+	in a composed normalization form, NFC or NFKC, to match decomposed
+	input.
+*/
+func (f *foldable) SetFolding(table *FoldingTable) {
+	f.folding = table
+}
+
+/*
+*
+
+	Returns the folding table set with SetFolding, or nil.
+*/
+func (f *foldable) GetFolding() *FoldingTable {
+	return f.folding
+}
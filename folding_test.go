@@ -0,0 +1,66 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFolding(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	words := []string{"dhamma", "dhammā", "dhātu", "saṃgha", "saṅgha", "samatha", "ñāṇa"}
+	for _, word := range words {
+		te.Insert(word)
+	}
+
+	table := NewFoldingTable(PaliFolding)
+	assert.Equal(t, "samgha", table.Fold("saṃgha"))
+	assert.Equal(t, "saṃgha", (*FoldingTable)(nil).Fold("saṃgha"))
+
+	// tails may start inside a character
+	topologies := []Topology{LOUDSTopology, BPTopology, TailTopology, PatriciaTopology, DAWGTopology, FastTopology}
+	for _, topology := range topologies {
+		dict, err := te.Freeze(topology)
+		assert.Nil(t, err)
+		assert.False(t, dict.Lookup("dhatu"), topology)
+		exact, _ := dict.LookupIndex("dhammā")
+		dict.SetFolding(table)
+
+		for _, word := range []string{"dhamma", "dhammā", "dhatu", "dhātu", "samgha", "sangha", "nana", "ñana", "ñāṇa"} {
+			assert.True(t, dict.Lookup(word), topology, word)
+		}
+		for _, word := range []string{"dham", "sagha", "nanas", "dhammaa", ""} {
+			assert.False(t, dict.Lookup(word), topology, word)
+		}
+
+		// the exact spelling is preferred
+		index, found := dict.LookupIndex("dhammā")
+		assert.True(t, found)
+		assert.Equal(t, exact, index, topology)
+		other, _ := dict.LookupIndex("dhamma")
+		assert.NotEqual(t, exact, other, topology)
+
+		assert.ElementsMatch(t, []string{"dhamma", "dhammā", "dhātu"}, dict.GetSuggestedWords("dha", 10), topology)
+		assert.ElementsMatch(t, []string{"dhamma", "dhammā"}, dict.GetSuggestedWords("dhamma", 10), topology)
+		assert.ElementsMatch(t, []string{"saṃgha", "samatha"}, dict.GetSuggestedWords("sam", 10), topology)
+		assert.Equal(t, []string{"ñāṇa"}, dict.GetSuggestedWords("nan", 10), topology)
+		assert.Empty(t, dict.GetSuggestedWords("x", 10), topology)
+
+		// a limit is shared by the spellings
+		suggested := dict.GetSuggestedWords("", 2)
+		assert.Subset(t, words, suggested, topology)
+		assert.Less(t, len(suggested), len(words), topology)
+
+		dict.SetFolding(nil)
+		assert.False(t, dict.Lookup("dhatu"), topology)
+		assert.True(t, dict.Lookup("dhātu"), topology)
+	}
+
+	ft := freezeTrie(&te)
+	ft.SetFolding(table)
+	assert.Equal(t, table, ft.GetFolding())
+	_, err := CreateAhoCorasick(&FrozenTrieMap{Ft: *ft})
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,10 @@
 package bits
 
-import "bytes"
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
 
 /*
 *
@@ -37,6 +41,109 @@ func (f *FrozenTrieNode) GetChild(index uint) FrozenTrieNode {
 	return f.trie.GetNodeByIndex(f.firstChild + index)
 }
 
+/*
+*
+
+	Returns the letter of the edge leading into the node.
+*/
+func (f *FrozenTrieNode) Letter() byte {
+	return f.letter
+}
+
+/*
+*
+
+	Returns true if the path to the node is a word.
+*/
+func (f *FrozenTrieNode) IsFinal() bool {
+	return f.final
+}
+
+/*
+*
+
+	Returns the index of the node in level order, as returned by
+	FrozenTrie.LookupIndex. The root is 0.
+*/
+func (f *FrozenTrieNode) Index() uint {
+	return f.index
+}
+
+// the position of the 1 bit of the node in the unary degrees of its parent
+func (f *FrozenTrieNode) edgePosition() uint {
+	return f.trie.directory.Select1(f.index + 1)
+}
+
+/*
+*
+
+	Returns the parent of the node. found is false for the root.
+*/
+func (f *FrozenTrieNode) Parent() (parent FrozenTrieNode, found bool) {
+	if f.index == 0 {
+		return *f, false
+	}
+	// the degrees are written in level order, one 0 bit ending each node,
+	// so the number of 0 bits before the edge is the parent's index.
+	return f.trie.GetNodeByIndex(f.trie.directory.Rank0(f.edgePosition()) - 1), true
+}
+
+/*
+*
+
+	Returns the next child of the node's parent. found is false for the
+	last child and for the root.
+*/
+func (f *FrozenTrieNode) NextSibling() (sibling FrozenTrieNode, found bool) {
+	if f.index == 0 || f.trie.data.Get(f.edgePosition()+1, 1) == 0 {
+		return *f, false
+	}
+	return f.trie.GetNodeByIndex(f.index + 1), true
+}
+
+/*
+*
+
+	Returns the previous child of the node's parent. found is false for the
+	first child and for the root.
+*/
+func (f *FrozenTrieNode) PrevSibling() (sibling FrozenTrieNode, found bool) {
+	if f.index == 0 || f.trie.data.Get(f.edgePosition()-1, 1) == 0 {
+		return *f, false
+	}
+	return f.trie.GetNodeByIndex(f.index - 1), true
+}
+
+/*
+*
+
+	Returns the number of edges between the root and the node.
+*/
+func (f *FrozenTrieNode) Depth() uint {
+	var depth uint = 0
+	for node, found := f.Parent(); found; node, found = node.Parent() {
+		depth++
+	}
+	return depth
+}
+
+/*
+*
+
+	Returns the letters on the path from the root to the node. For a leaf
+	with a tail, the tail is not included.
+*/
+func (f *FrozenTrieNode) Path() string {
+	var letters []byte
+	for node := *f; node.index > 0; node, _ = node.Parent() {
+		letters = append(letters, node.letter)
+	}
+	for i, j := 0, len(letters)-1; i < j; i, j = i+1, j-1 {
+		letters[i], letters[j] = letters[j], letters[i]
+	}
+	return string(letters)
+}
+
 /*
 *
 
@@ -51,17 +158,117 @@ func (f *FrozenTrieNode) GetChild(index uint) FrozenTrieNode {
 */
 type FrozenTrie struct {
 	data        BitString
-	directory   RankDirectory
+	directory   RankSelect
 	letterStart uint
+	tails       *Tails
+	foldable
+	normalizer
+}
+
+func (f *FrozenTrie) Init(data, directoryData string, nodeCount uint) error {
+	rd := RankDirectory{}
+	rd.Init(directoryData, data, nodeCount*2+1, L1, L2)
+	return f.InitWithRankSelect(data, &rd, nodeCount)
 }
 
-func (f *FrozenTrie) Init(data, directoryData string, nodeCount uint) {
+/*
+*
+
+	Like Init, but navigates the trie with the given RankSelect, which must
+	index the first nodeCount*2+1 bits of data. Use CreateRankSelect to
+	build one of the kind you want. The normalization form of the keys is
+	read from the end of data, where Trie.Encode records it; returns an
+	error if it is unknown.
+*/
+func (f *FrozenTrie) InitWithRankSelect(data string, directory RankSelect, nodeCount uint) error {
 	f.data.Init(data)
-	f.directory.Init(directoryData, data, nodeCount*2+1, L1, L2)
+	f.directory = directory
+	f.tails = nil
 
 	// The position of the first bit of the data in 0th node. In non-root
 	// nodes, this would contain 6-bit letters.
 	f.letterStart = nodeCount*2 + 1
+	return f.readTrailer(&f.data, f.letterStart+nodeCount*dataBits)
+}
+
+/*
+*
+
+	Encode the trie like Encode, together with a RankSelect of the given
+	kind over its topology, into a single string to be read with
+	FrozenTrie.InitFromData. With withTails, the chains ending in a leaf are
+	cut into tails as by EncodeWithTails.
+*/
+func (t *Trie) EncodeLOUDS(kind RankSelectKind, withTails bool) string {
+	var encoding, tailData string
+	nodeCount := t.GetNodeCount()
+	if withTails {
+		encoding, tailData, nodeCount, _ = t.EncodeWithTails(kind)
+	} else {
+		encoding, _ = t.Encode()
+	}
+	directory := CreateRankSelect(kind, encoding, nodeCount*2+1)
+
+	header := BitWriter{}
+	header.Write(nodeCount, 32)
+	return joinSections(header.GetData(), encoding, EncodeRankSelect(directory), tailData)
+}
+
+/*
+*
+
+	Initializes the trie from the string returned by Trie.EncodeLOUDS.
+*/
+func (f *FrozenTrie) InitFromData(data string) error {
+	sections, err := splitSections(data, 4)
+	if err != nil {
+		return err
+	}
+	if len(sections[0]) < 4 {
+		return fmt.Errorf("frozentrie: header too short")
+	}
+	header := BitString{}
+	header.Init(sections[0])
+	nodeCount := header.Get(0, 32)
+
+	directory, err := DecodeRankSelect(sections[2], sections[1], nodeCount*2+1)
+	if err != nil {
+		return err
+	}
+	if err := f.InitWithRankSelect(sections[1], directory, nodeCount); err != nil {
+		return err
+	}
+	if sections[3] != "" {
+		return f.InitTails(sections[3])
+	}
+	return nil
+}
+
+/*
+*
+
+	Returns the number of nodes in the trie.
+*/
+func (f *FrozenTrie) GetNodeCount() uint {
+	return (f.letterStart - 1) / 2
+}
+
+/*
+*
+
+	Returns the encoded trie.
+*/
+func (f *FrozenTrie) GetData() string {
+	return f.data.GetData()
+}
+
+/*
+*
+
+	Returns the RankSelect used to navigate the trie.
+*/
+func (f *FrozenTrie) GetDirectory() RankSelect {
+	return f.directory
 }
 
 /*
@@ -74,11 +281,11 @@ func (f *FrozenTrie) GetNodeByIndex(index uint) FrozenTrieNode {
 	// retrieve the (dataBits)-bit letter.
 	final := (f.data.Get(f.letterStart+index*dataBits, 1) == 1)
 	letter := uint8(f.data.Get(f.letterStart+index*dataBits+1, (dataBits - 1)))
-	firstChild := f.directory.Select(0, index+1) - index
+	firstChild := f.directory.Select0(index+1) - index
 
 	// Since the nodes are in level order, this nodes children must go up
 	// until the next node's children start.
-	childOfNextNode := f.directory.Select(0, index+2) - index - 1
+	childOfNextNode := f.directory.Select0(index+2) - index - 1
 
 	return FrozenTrieNode{
 		trie:       f,
@@ -107,47 +314,118 @@ func (f *FrozenTrie) GetRoot() FrozenTrieNode {
 	in the trie.
 */
 func (f *FrozenTrie) Lookup(word string) bool {
-	node := f.GetRoot()
-	wordBytes := []byte(word)
-	for _, i := range wordBytes {
-		var child FrozenTrieNode
-		var j uint = 0
-		for ; j < node.GetChildCount(); j++ {
-			child = node.GetChild(j)
-			if child.letter == i {
-				break
-			}
-		}
+	_, found := f.LookupIndex(word)
+	return found
+}
 
-		if j == node.GetChildCount() {
-			return false
+func (f *FrozenTrie) LookupIndex(word string) (index uint, found bool) {
+	for _, match := range f.descend(word) {
+		if match.position.final() {
+			return match.position.node.index, true
 		}
-		node = child
 	}
+	return 0, false
+}
+
+/*
+*
+
+	A position in the trie: a node, or the bytes of the tail of a leaf
+	matched so far.
+*/
+type triePosition struct {
+	node    FrozenTrieNode
+	tail    string
+	tailPos int
+}
 
-	return node.final
+// Returns true if the letters leading to the position are a word.
+func (p triePosition) final() bool {
+	if p.tail != "" {
+		return p.tailPos == len(p.tail)
+	}
+	return p.node.final
 }
 
-func (f *FrozenTrie) LookupIndex(word string) (index uint, found bool) {
-	node := f.GetRoot()
-	wordBytes := []byte(word)
-	for _, i := range wordBytes {
-		var child FrozenTrieNode
-		var j uint = 0
-		for ; j < node.GetChildCount(); j++ {
-			child = node.GetChild(j)
-			if child.letter == i {
-				break
-			}
+// Returns the position reached from p by the letter.
+func (f *FrozenTrie) step(p triePosition, letter byte) (triePosition, bool) {
+	if p.tail != "" {
+		if p.tailPos < len(p.tail) && p.tail[p.tailPos] == letter {
+			p.tailPos++
+			return p, true
 		}
+		return p, false
+	}
+	child, found := f.child(p.node, letter)
+	if !found {
+		return p, false
+	}
+	return triePosition{node: child, tail: f.getTail(child)}, true
+}
+
+/*
+*
 
-		if j == node.GetChildCount() {
+	Follows the spellings of word down from start, as described for
+	FoldingTable.expand, calling visit at each character boundary with the
+	position reached.
+*/
+func (f *FrozenTrie) follow(start triePosition, word string, visit func(p triePosition, spelling string, covered int)) {
+	positions := []triePosition{start}
+	f.folding.expand(word, 0, func(from int, letter byte) (int, bool) {
+		next, found := f.step(positions[from], letter)
+		if !found {
 			return 0, false
 		}
-		node = child
+		positions = append(positions, next)
+		return len(positions) - 1, true
+	}, func(at int, spelling string, covered int) {
+		visit(positions[at], spelling, covered)
+	})
+}
+
+// A position at the end of a spelling of the word passed to descend.
+type descent struct {
+	position triePosition
+	spelling string
+}
+
+/*
+*
+
+	Follows the letters of word, once normalized like the keys, down from
+	the root, or with a folding table, every spelling that folds like it,
+	the exact one first. Returns the positions at the end of each spelling
+	found.
+*/
+func (f *FrozenTrie) descend(word string) []descent {
+	root := descent{position: triePosition{node: f.GetRoot()}}
+	return f.extend([]descent{root}, f.normalizeWord(word))
+}
+
+// Follows text, normalized like the keys, on from each of the descents,
+// the way descend does from the root.
+func (f *FrozenTrie) extend(from []descent, text string) []descent {
+	var result []descent
+	for _, d := range from {
+		f.follow(d.position, text, func(p triePosition, spelling string, covered int) {
+			if covered == len(text) {
+				result = append(result, descent{p, d.spelling + spelling})
+			}
+		})
 	}
+	return result
+}
 
-	return node.index, node.final
+// Returns the child of node with the given letter.
+func (f *FrozenTrie) child(node FrozenTrieNode, letter byte) (FrozenTrieNode, bool) {
+	for j := uint(0); j < node.GetChildCount(); j++ {
+		child := node.GetChild(j)
+		if child.letter == letter {
+			return child, true
+		}
+	}
+	return node, false
 }
 
 /*
@@ -166,6 +444,115 @@ func (t *FrozenTrie) Apply(fn func(FrozenTrieNode)) {
 	}
 }
 
+/*
+*
+
+	WalkAction tells Walk how to continue after visiting a node.
+*/
+type WalkAction int
+
+const (
+	// Visit the children of the node, then carry on.
+	Continue WalkAction = iota
+	// Skip the children of the node, like filepath.SkipDir.
+	SkipChildren
+	// End the walk.
+	Stop
+)
+
+/*
+*
+
+	Call fn on each node, with its key, in depth-first order, visiting the
+	children of each node in increasing order of their letters. For a trie
+	built from sorted words, this visits the words in sorted order. The key
+	of a leaf with a tail includes the tail. Returns false if fn stopped the
+	walk.
+*/
+func (t *FrozenTrie) Walk(fn func(key string, node FrozenTrieNode) WalkAction) bool {
+	var key []byte
+	var visit func(node FrozenTrieNode) bool
+	visit = func(node FrozenTrieNode) bool {
+		switch fn(string(key)+t.getTail(node), node) {
+		case Stop:
+			return false
+		case SkipChildren:
+			return true
+		}
+
+		children := make([]FrozenTrieNode, node.GetChildCount())
+		for i := range children {
+			children[i] = node.GetChild(uint(i))
+		}
+		sort.Slice(children, func(i, j int) bool {
+			return children[i].letter < children[j].letter
+		})
+		for _, child := range children {
+			key = append(key, child.letter)
+			if !visit(child) {
+				return false
+			}
+			key = key[:len(key)-1]
+		}
+		return true
+	}
+	return visit(t.GetRoot())
+}
+
+/*
+*
+
+	Rebuilds the editable Trie that the trie was encoded from, with the same
+	letters, final nodes and order of children. Tails are expanded back into
+	nodes, and the normalization of the keys is kept.
+*/
+func (t *FrozenTrie) Thaw() *Trie {
+	result := &Trie{}
+	result.Init()
+	result.normalizer = t.normalizer
+
+	// nodes are numbered in level order, so each parent is created before
+	// its children.
+	nodes := make([]*TrieNode, t.GetNodeCount())
+	nodes[0] = result.root
+	for index := uint(0); index < t.GetNodeCount(); index++ {
+		frozen := t.GetNodeByIndex(index)
+		node := nodes[index]
+		node.final = frozen.final
+		if node.final {
+			result.keyCount++
+		}
+
+		for i := uint(0); i < frozen.GetChildCount(); i++ {
+			child := frozen.GetChild(i)
+			nodes[child.index] = &TrieNode{letter: child.letter}
+			node.children = append(node.children, nodes[child.index])
+			result.nodeCount++
+		}
+
+		if tail := t.getTail(frozen); tail != "" {
+			node.final = false
+			for i := 0; i < len(tail); i++ {
+				next := &TrieNode{letter: tail[i]}
+				node.children = append(node.children, next)
+				result.nodeCount++
+				node = next
+			}
+			node.final = true
+		}
+	}
+	return result
+}
+
+/*
+*
+
+	Same as ft.Thaw().
+*/
+func NewTrieFromFrozen(ft *FrozenTrie) *Trie {
+	return ft.Thaw()
+}
+
 func (t *FrozenTrie) GetLastLexographicKey() string {
 	var result bytes.Buffer
 	node := t.GetRoot()
@@ -173,6 +560,7 @@ func (t *FrozenTrie) GetLastLexographicKey() string {
 	for {
 		childCount := node.GetChildCount()
 		if childCount == 0 {
+			result.WriteString(t.getTail(node))
 			return result.String()
 		}
 		node = node.GetChild(childCount - 1)
@@ -1,6 +1,11 @@
 package bits
 
-import "testing"
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func lookupTestCase(t *testing.T, ft *FrozenTrie, keys RankDirectory, word string, expected bool) {
 	_, found := ft.LookupIndex(word)
@@ -65,3 +70,113 @@ func TestLookup(t *testing.T) {
 	lookupTestCase(t, &ft, keys, "alphaph", false)
 	lookupTestCase(t, &ft, keys, "alphapha", true)
 }
+
+func TestNodeNavigation(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertInAlphabeticalOrder(&te)
+	ft := freezeTrie(&te)
+
+	root := ft.GetRoot()
+	_, found := root.Parent()
+	assert.False(t, found)
+	_, found = root.NextSibling()
+	assert.False(t, found)
+	assert.Equal(t, uint(0), root.Depth())
+	assert.Equal(t, "", root.Path())
+
+	ft.Apply(func(node FrozenTrieNode) {
+		for i := uint(0); i < node.GetChildCount(); i++ {
+			child := node.GetChild(i)
+			parent, found := child.Parent()
+			assert.True(t, found)
+			assert.Equal(t, node.Index(), parent.Index())
+			assert.Equal(t, node.Path()+string([]byte{child.Letter()}), child.Path())
+			assert.Equal(t, node.Depth()+1, child.Depth())
+
+			next, found := child.NextSibling()
+			assert.Equal(t, i+1 < node.GetChildCount(), found)
+			if found {
+				assert.Equal(t, child.Index()+1, next.Index())
+			}
+			prev, found := child.PrevSibling()
+			assert.Equal(t, i > 0, found)
+			if found {
+				assert.Equal(t, child.Index()-1, prev.Index())
+			}
+		}
+	})
+
+	index, _ := ft.LookupIndex("hello")
+	node := ft.GetNodeByIndex(index)
+	assert.Equal(t, "hello", node.Path())
+	assert.True(t, node.IsFinal())
+	assert.Equal(t, byte('o'), node.Letter())
+	assert.Equal(t, uint(5), node.Depth())
+}
+
+func TestWalk(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertNotInAlphabeticalOrder(&te)
+	ft := freezeTrie(&te)
+
+	var keys, words []string
+	ft.Walk(func(key string, node FrozenTrieNode) WalkAction {
+		keys = append(keys, key)
+		if node.IsFinal() {
+			words = append(words, key)
+		}
+		return Continue
+	})
+	assert.True(t, sort.StringsAreSorted(keys))
+	assert.Equal(t, []string{"alphapha", "apple", "hello", "jello", "lamp", "orange", "quiz"}, words)
+
+	var skipped []string
+	ft.Walk(func(key string, node FrozenTrieNode) WalkAction {
+		if node.IsFinal() {
+			skipped = append(skipped, key)
+		}
+		if key == "a" {
+			return SkipChildren
+		}
+		return Continue
+	})
+	assert.Equal(t, []string{"hello", "jello", "lamp", "orange", "quiz"}, skipped)
+
+	var visited []string
+	done := ft.Walk(func(key string, node FrozenTrieNode) WalkAction {
+		visited = append(visited, key)
+		if key == "ap" {
+			return Stop
+		}
+		return Continue
+	})
+	assert.False(t, done)
+	assert.Len(t, visited, 10)
+	assert.Equal(t, "ap", visited[9])
+}
+
+func TestThaw(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertNotInAlphabeticalOrder(&te)
+	te.Insert("quizzes")
+	teData, _ := te.Encode()
+
+	for _, thawed := range []*Trie{freezeTrie(&te).Thaw(), NewTrieFromFrozen(freezeTrieWithTails(t, &te))} {
+		thawedData, _ := thawed.Encode()
+		assert.Equal(t, teData, thawedData)
+		assert.Equal(t, te.GetNodeCount(), thawed.GetNodeCount())
+		assert.Equal(t, te.Len(), thawed.Len())
+
+		thawed.Insert("zebra")
+		thawed.Delete("hello")
+		assert.True(t, thawed.Contains("zebra"))
+		assert.False(t, thawed.Contains("hello"))
+		assert.True(t, thawed.Contains("quizzes"))
+	}
+}
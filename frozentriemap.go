@@ -2,6 +2,7 @@ package bits
 
 import (
 	"bytes"
+	"fmt"
 )
 
 /*
@@ -16,16 +17,34 @@ FrozenTrieMap maps words in a trie onto indices.
 */
 type FrozenTrieMap struct {
 	Ft    FrozenTrie
-	keys  RankDirectory
+	keys  RankSelect
 	words uint
+	// the original spellings of the keys, set by CreateCaseFolded
+	exceptions *CaseExceptions
 }
 
-func (f *FrozenTrieMap) Create(teData string, nodeCount uint) {
-	finalNodes := BitWriter{}
+func (f *FrozenTrieMap) Create(teData string, nodeCount uint) error {
+	return f.CreateWithKinds(teData, nodeCount, RankDirectoryKind, RankDirectoryKind)
+}
+
+/*
+*
 
-	rd := CreateRankDirectory(teData, nodeCount*2+1, L1, L2)
+	Like Create, but lets you choose the RankSelect implementation used for
+	the trie topology and the one used for the bitmap of final nodes.
+	Returns an error if the normalization form recorded in teData is
+	unknown.
+*/
+func (f *FrozenTrieMap) CreateWithKinds(teData string, nodeCount uint, topology, keys RankSelectKind) error {
+	finalNodes := BitWriter{}
 
-	f.Ft.Init(teData, rd.GetData(), nodeCount)
+	f.words = 0
+	f.exceptions = nil
+	err := f.Ft.InitWithRankSelect(teData,
+		CreateRankSelect(topology, teData, nodeCount*2+1), nodeCount)
+	if err != nil {
+		return err
+	}
 
 	f.Ft.Apply(func(node FrozenTrieNode) {
 		if node.final {
@@ -36,46 +55,143 @@ func (f *FrozenTrieMap) Create(teData string, nodeCount uint) {
 		}
 	})
 
-	f.keys = CreateRankDirectory(finalNodes.GetData(), nodeCount, L1, L2)
+	f.keys = CreateRankSelect(keys, finalNodes.GetData(), nodeCount)
+	return nil
 }
 
 func (f *FrozenTrieMap) Init(ft FrozenTrie, keys RankDirectory) {
+	f.InitWithRankSelect(ft, &keys)
+}
+
+/*
+*
+
+	Like Init, but takes any RankSelect over the bitmap of final nodes.
+*/
+func (f *FrozenTrieMap) InitWithRankSelect(ft FrozenTrie, keys RankSelect) {
 	f.Ft = ft
 	f.keys = keys
+	f.exceptions = nil
+	f.words = 0
+	if keys.Len() > 0 {
+		f.words = keys.Rank1(keys.Len() - 1)
+	}
 }
 
-func (f *FrozenTrieMap) LookupIndex(word string) (index uint, found bool) {
-	node := f.Ft.GetRoot()
-	wordBytes := []byte(word)
-	for _, i := range wordBytes {
-		var child FrozenTrieNode
-		var j uint = 0
-		for ; j < node.GetChildCount(); j++ {
-			child = node.GetChild(j)
-			if child.letter == i {
-				break
-			}
+/*
+*
+
+	Returns the map encoded as a single string: the trie, the bitmap of
+	final nodes, both rank/select indexes tagged with their kinds, and the
+	tails if the trie has any, and the case exceptions if the keys are case
+	folded. The header records the normalization form of the trie and
+	whether its keys are case folded. Use InitFromData to restore it.
+*/
+func (f *FrozenTrieMap) GetData() string {
+	header := BitWriter{}
+	header.Write(f.Ft.GetNodeCount(), 32)
+	f.Ft.writeHeader(&header)
+
+	var tails string
+	if f.Ft.tails != nil {
+		tails = f.Ft.tails.GetData()
+	}
+	var exceptions string
+	if f.exceptions != nil {
+		exceptions = f.exceptions.GetData()
+	}
+
+	return joinSections(header.GetData(), f.Ft.GetData(),
+		EncodeRankSelect(f.Ft.directory), rankSelectBits(f.keys),
+		EncodeRankSelect(f.keys), tails, exceptions)
+}
+
+/*
+*
+
+	Restores a map from the string returned by GetData.
+*/
+func (f *FrozenTrieMap) InitFromData(data string) error {
+	sections, err := splitSections(data, 7)
+	if err != nil {
+		return err
+	}
+	if len(sections[0]) < 6 {
+		return fmt.Errorf("frozentriemap: header too short")
+	}
+	header := BitString{}
+	header.Init(sections[0])
+	nodeCount := header.Get(0, 32)
+
+	directory, err := DecodeRankSelect(sections[2], sections[1], nodeCount*2+1)
+	if err != nil {
+		return err
+	}
+	keys, err := DecodeRankSelect(sections[4], sections[3], nodeCount)
+	if err != nil {
+		return err
+	}
+
+	ft := FrozenTrie{}
+	if err := ft.InitWithRankSelect(sections[1], directory, nodeCount); err != nil {
+		return err
+	}
+	if err := ft.readHeader(&header, 32); err != nil {
+		return err
+	}
+	if sections[5] != "" {
+		if err := ft.InitTails(sections[5]); err != nil {
+			return err
 		}
+	}
+	f.InitWithRankSelect(ft, keys)
 
-		if j == node.GetChildCount() {
-			return 0, false
+	if f.Ft.foldCase {
+		f.exceptions = &CaseExceptions{}
+		if err := f.exceptions.Init(sections[6]); err != nil {
+			return err
 		}
-		node = child
 	}
+	return nil
+}
+
+/*
+*
+
+	Makes lookups normalize words to the given form, which must be the one
+	the trie was built with. Create and InitFromData read it from the
+	encoding, and GetData records it.
+*/
+func (f *FrozenTrieMap) SetNormalization(form NormalizationForm) error {
+	return f.Ft.SetNormalization(form)
+}
+
+/*
+*
 
-	return f.keys.Rank(1, node.index), node.final
+	Returns the number of keys in the map. Key indices run from 1 to this
+	number.
+*/
+func (f *FrozenTrieMap) GetKeyCount() uint {
+	return f.words
+}
+
+func (f *FrozenTrieMap) LookupIndex(word string) (index uint, found bool) {
+	nodeIndex, found := f.Ft.LookupIndex(word)
+	if !found {
+		return 0, false
+	}
+	return f.keys.Rank1(nodeIndex), true
 }
 
 func (f *FrozenTrieMap) ReverseLookup(keyIndex uint) (word string) {
-	var resultBytes []byte
-	trieNodeNumber := f.keys.Select(1, keyIndex)
-	for trieNodeNumber > 0 {
-		node := f.Ft.GetNodeByIndex(trieNodeNumber)
-		resultBytes = append([]byte{node.letter}, resultBytes...)
-		parentOffset := f.Ft.directory.Select(1, trieNodeNumber+1)
-		trieNodeNumber = f.Ft.directory.Rank(0, parentOffset) - 1
+	if f.exceptions != nil {
+		if original, ok := f.exceptions.Get(keyIndex); ok {
+			return original
+		}
 	}
-	return string(resultBytes)
+	node := f.Ft.GetNodeByIndex(f.keys.Select1(keyIndex))
+	return node.Path() + f.Ft.getTail(node)
 }
 
 func (f *FrozenTrieMap) GetBuffer() []byte {
@@ -87,7 +203,7 @@ func (f *FrozenTrieMap) GetBuffer() []byte {
 
 func (f *FrozenTrieMap) GetOffsets() []byte {
 	var result bytes.Buffer
-	result.WriteString(f.keys.data.GetData())
-	result.WriteString(f.keys.directory.GetData())
+	result.WriteString(rankSelectBits(f.keys))
+	result.WriteString(f.keys.GetData())
 	return result.Bytes()
 }
@@ -9,17 +9,24 @@ func tlookupMap(t *testing.T, ftm *FrozenTrieMap, word string, expected bool) {
 	}
 	t.Log(word, index, found)
 	if found {
-		t.Log(ftm.keys.Rank(1, index))
+		t.Log(ftm.keys.Rank1(index))
 	}
 }
 
+func createTestMap(te *Trie) FrozenTrieMap {
+	teData, _ := te.Encode()
+	ftm := FrozenTrieMap{}
+	ftm.Create(teData, te.GetNodeCount())
+	return ftm
+}
+
 func TestMapLookup(t *testing.T) {
+	useByteLetters(t)
 	te := Trie{}
 	te.Init()
 	insertNotInAlphabeticalOrder(&te)
 
-	ftm := FrozenTrieMap{}
-	ftm.Create(te)
+	ftm := createTestMap(&te)
 
 	tlookupMap(t, &ftm, "apple", true)
 	tlookupMap(t, &ftm, "appl", false)
@@ -35,6 +42,7 @@ func TestMapLookup(t *testing.T) {
 }
 
 func TestMapReverseLookup(t *testing.T) {
+	useByteLetters(t)
 	te := Trie{}
 	te.Init()
 
@@ -45,8 +53,7 @@ func TestMapReverseLookup(t *testing.T) {
 	}
 	insertNotInAlphabeticalOrder(&te)
 
-	ftm := FrozenTrieMap{}
-	ftm.Create(te)
+	ftm := createTestMap(&te)
 
 	// for i := range words {
 	for i := 0; i < 7; i++ {
@@ -0,0 +1,411 @@
+package bits
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+/*
+*
+
+	A state of the FST while it is being built. Once frozen, a state is
+	shared by every equivalent part of the automaton.
+*/
+type fstState struct {
+	final       bool
+	finalOutput uint64
+	arcs        []fstArc
+	// the position of the state in the encoding, set when frozen.
+	address uint
+}
+
+type fstArc struct {
+	label  byte
+	output uint64
+	target *fstState
+}
+
+/*
+*
+
+	FSTBuilder builds a minimal finite state transducer mapping keys to
+	uint64 outputs, in the style of Lucene and vellum. Keys must be inserted
+	in strictly increasing order. States are frozen and deduplicated as soon
+	as no later key can reach them, so memory use is bounded by the length of
+	the longest key plus the size of the minimal automaton.
+
+	Outputs live on the arcs: the output of a key is the sum of the outputs
+	along its path plus the final output of its last state. While building,
+	the shared part of two outputs is pushed towards the root.
+
+	Keys are normalized to the form set by SetNormalization, which must be
+	called before the first Insert, and the FST records it.
+*/
+type FSTBuilder struct {
+	normalizer
+	// unfinished[i] is the state at depth i on the path of the last key.
+	// Its last arc leads to unfinished[i+1] and has no target yet.
+	unfinished []*fstState
+	register   map[string]*fstState
+	lastKey    string
+	numKeys    uint
+	bits       BitWriter
+}
+
+/*
+*
+
+	Creates an empty builder.
+*/
+func NewFSTBuilder() *FSTBuilder {
+	return &FSTBuilder{
+		unfinished: []*fstState{{}},
+		register:   map[string]*fstState{},
+	}
+}
+
+/*
+*
+
+	Adds a key with its output. Returns an error if the key, once
+	normalized, is not greater than the previous one.
+*/
+func (b *FSTBuilder) Insert(key string, output uint64) error {
+	key = b.normalizeWord(key)
+	if b.numKeys > 0 && key <= b.lastKey {
+		return fmt.Errorf("fst: key %q inserted after %q", key, b.lastKey)
+	}
+	if output == math.MaxUint64 {
+		return fmt.Errorf("fst: output of %q is too large", key)
+	}
+
+	prefixLen := 0
+	for prefixLen < len(key) && prefixLen < len(b.lastKey) &&
+		key[prefixLen] == b.lastKey[prefixLen] {
+		prefixLen++
+	}
+
+	// the states after the common prefix can no longer change.
+	b.freeze(prefixLen)
+
+	for i := prefixLen; i < len(key); i++ {
+		node := b.unfinished[i]
+		node.arcs = append(node.arcs, fstArc{label: key[i]})
+		b.unfinished = append(b.unfinished, &fstState{})
+	}
+	b.unfinished[len(key)].final = true
+
+	// push the part of the outputs along the common prefix that the new
+	// output does not share down to the next state.
+	for i := 0; i < prefixLen; i++ {
+		arc := &b.unfinished[i].arcs[len(b.unfinished[i].arcs)-1]
+		common := arc.output
+		if output < common {
+			common = output
+		}
+		if rest := arc.output - common; rest > 0 {
+			next := b.unfinished[i+1]
+			for j := range next.arcs {
+				next.arcs[j].output += rest
+			}
+			if next.final {
+				next.finalOutput += rest
+			}
+		}
+		arc.output = common
+		output -= common
+	}
+
+	if prefixLen < len(key) {
+		node := b.unfinished[prefixLen]
+		node.arcs[len(node.arcs)-1].output = output
+	} else {
+		b.unfinished[prefixLen].finalOutput = output
+	}
+
+	b.lastKey = key
+	b.numKeys++
+	return nil
+}
+
+// Freezes the unfinished states deeper than depth and attaches them to
+// their parents.
+func (b *FSTBuilder) freeze(depth int) {
+	for i := len(b.unfinished) - 1; i > depth; i-- {
+		parent := b.unfinished[i-1]
+		parent.arcs[len(parent.arcs)-1].target = b.compile(b.unfinished[i])
+	}
+	b.unfinished = b.unfinished[:depth+1]
+}
+
+// Returns the registered state equivalent to node, writing node to the
+// encoding if there is none.
+func (b *FSTBuilder) compile(node *fstState) *fstState {
+	var signature bytes.Buffer
+	var buf [binary.MaxVarintLen64]byte
+	if node.final {
+		signature.WriteByte(1)
+	} else {
+		signature.WriteByte(0)
+	}
+	signature.Write(buf[:binary.PutUvarint(buf[:], node.finalOutput)])
+	for _, arc := range node.arcs {
+		signature.WriteByte(arc.label)
+		signature.Write(buf[:binary.PutUvarint(buf[:], arc.output)])
+		signature.Write(buf[:binary.PutUvarint(buf[:], uint64(arc.target.address))])
+	}
+	if existing, ok := b.register[signature.String()]; ok {
+		return existing
+	}
+
+	// Each state is written as its final bit, its number of arcs, its final
+	// output if final, and its arcs. An arc is its letter in (dataBits-1)
+	// bits, its output, and the distance back to its target, which is
+	// always written first. Numbers use the Elias codes.
+	node.address = b.bits.Len()
+	if node.final {
+		b.bits.Write(1, 1)
+	} else {
+		b.bits.Write(0, 1)
+	}
+	b.bits.WriteGamma(uint64(len(node.arcs)) + 1)
+	if node.final {
+		b.bits.WriteDelta(node.finalOutput + 1)
+	}
+	for _, arc := range node.arcs {
+		b.bits.Write(uint(arc.label), dataBits-1)
+		b.bits.WriteDelta(arc.output + 1)
+		b.bits.WriteDelta(uint64(node.address - arc.target.address))
+	}
+
+	b.register[signature.String()] = node
+	return node
+}
+
+/*
+*
+
+	Freezes the remaining states and returns the encoded FST, to be read
+	with FST.Init.
+*/
+func (b *FSTBuilder) Finish() string {
+	b.freeze(0)
+	root := b.unfinished[0]
+	// the root may be equivalent to another state, so write it last to
+	// keep it from being merged.
+	rootAddress := b.bits.Len()
+	b.register = map[string]*fstState{}
+	b.compile(root)
+
+	header := BitWriter{}
+	header.Write(rootAddress, 32)
+	header.Write(b.numKeys, 32)
+	header.Write(dataBits-1, 8)
+	b.writeHeader(&header)
+	return joinSections(header.GetData(), b.bits.GetData())
+}
+
+/*
+*
+
+	FST is a finite state transducer built by FSTBuilder, mapping keys to
+	uint64 outputs. Keys are normalized like those of the builder.
+*/
+type FST struct {
+	normalizer
+	data       BitString
+	root       uint
+	numKeys    uint
+	letterBits uint
+}
+
+/*
+*
+
+	Initializes the FST from the string returned by FSTBuilder.Finish.
+	Every state is decoded once, so that truncated or corrupt data gives an
+	error here rather than a panic in a later query.
+*/
+func (f *FST) Init(data string) error {
+	sections, err := splitSections(data, 2)
+	if err != nil {
+		return err
+	}
+	if len(sections[0]) < 11 {
+		return fmt.Errorf("fst: header too short")
+	}
+	header := BitString{}
+	header.Init(sections[0])
+	f.root = header.Get(0, 32)
+	f.numKeys = header.Get(32, 32)
+	f.letterBits = header.Get(64, 8)
+	if err := f.readHeader(&header, 72); err != nil {
+		return err
+	}
+	f.data.Init(sections[1])
+	if f.letterBits > 8 {
+		return fmt.Errorf("fst: letters of %d bits", f.letterBits)
+	}
+	return f.check()
+}
+
+// Decodes every state reachable from the root, checking that it lies within
+// the data and that its arcs lead back to states written before it.
+func (f *FST) check() error {
+	checked := map[uint]bool{}
+	stack := []uint{f.root}
+	for len(stack) > 0 {
+		address := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if checked[address] {
+			continue
+		}
+		checked[address] = true
+
+		_, _, numArcs, p, err := f.readState(address)
+		if err != nil {
+			return err
+		}
+		for a := uint(0); a < numArcs; a++ {
+			_, _, target, err := f.readArc(address, &p)
+			if err != nil {
+				return err
+			}
+			stack = append(stack, target)
+		}
+	}
+	return nil
+}
+
+/*
+*
+
+	Returns the number of keys.
+*/
+func (f *FST) Len() uint {
+	return f.numKeys
+}
+
+// Reads the state at address, returning the position of its first arc.
+func (f *FST) readState(address uint) (final bool, finalOutput uint64, numArcs, p uint, err error) {
+	if address >= f.data.length {
+		return false, 0, 0, 0, fmt.Errorf("fst: state at %d is past the end of the data", address)
+	}
+	p = address
+	final = f.data.Get(p, 1) == 1
+	p++
+	arcs, err := f.data.ReadGamma(&p)
+	if err != nil {
+		return false, 0, 0, 0, err
+	}
+	if final {
+		if finalOutput, err = f.data.ReadDelta(&p); err != nil {
+			return false, 0, 0, 0, err
+		}
+		finalOutput--
+	}
+	return final, finalOutput, uint(arcs - 1), p, nil
+}
+
+// Reads the arc at *p of the state at address, and advances *p past it.
+func (f *FST) readArc(address uint, p *uint) (label byte, output uint64, target uint, err error) {
+	if *p+f.letterBits > f.data.length {
+		return 0, 0, 0, fmt.Errorf("fst: arc at %d is past the end of the data", *p)
+	}
+	label = byte(f.data.Get(*p, f.letterBits))
+	*p += f.letterBits
+	if output, err = f.data.ReadDelta(p); err != nil {
+		return 0, 0, 0, err
+	}
+	distance, err := f.data.ReadDelta(p)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if distance > uint64(address) {
+		return 0, 0, 0, fmt.Errorf("fst: arc at %d leads before the start of the data", *p)
+	}
+	return label, output - 1, address - uint(distance), nil
+}
+
+// Follows key, once normalized, from the root, returning the state reached and the sum of the
+// outputs along the way.
+func (f *FST) descend(key string) (address uint, output uint64, found bool) {
+	address = f.root
+	key = f.normalizeWord(key)
+	for i := 0; i < len(key); i++ {
+		_, _, numArcs, p, err := f.readState(address)
+		if err != nil {
+			return 0, 0, false
+		}
+		found = false
+		for a := uint(0); a < numArcs; a++ {
+			label, arcOutput, target, err := f.readArc(address, &p)
+			if err != nil {
+				return 0, 0, false
+			}
+			if label == key[i] {
+				address = target
+				output += arcOutput
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, 0, false
+		}
+	}
+	return address, output, true
+}
+
+/*
+*
+
+	Returns the output of the key, and whether the key exists.
+*/
+func (f *FST) Get(key string) (output uint64, found bool) {
+	address, output, found := f.descend(key)
+	if !found {
+		return 0, false
+	}
+	final, finalOutput, _, _, err := f.readState(address)
+	if err != nil || !final {
+		return 0, false
+	}
+	return output + finalOutput, true
+}
+
+/*
+*
+
+	Calls fn with every key starting with prefix and its output, in
+	increasing order of the keys. Stops early if fn returns false.
+*/
+func (f *FST) Iterate(prefix string, fn func(key string, output uint64) bool) {
+	address, output, found := f.descend(prefix)
+	if !found {
+		return
+	}
+	f.iterate(address, []byte(f.normalizeWord(prefix)), output, fn)
+}
+
+func (f *FST) iterate(address uint, key []byte, output uint64, fn func(string, uint64) bool) bool {
+	final, finalOutput, numArcs, p, err := f.readState(address)
+	if err != nil {
+		return false
+	}
+	if final && !fn(string(key), output+finalOutput) {
+		return false
+	}
+	for a := uint(0); a < numArcs; a++ {
+		label, arcOutput, target, err := f.readArc(address, &p)
+		if err != nil {
+			return false
+		}
+		if !f.iterate(target, append(key, label), output+arcOutput, fn) {
+			return false
+		}
+	}
+	return true
+}
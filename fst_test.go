@@ -0,0 +1,62 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFST(t *testing.T) {
+	useByteLetters(t)
+	keys := []string{"", "jul", "jump", "jumped", "jumps", "june", "pump", "pumped", "pumps", "walk", "walked", "zoo"}
+	outputs := []uint64{9, 7, 100, 2, 100, 1 << 40, 3, 2, 100, 0, 55, 1<<64 - 2}
+
+	b := NewFSTBuilder()
+	for i, key := range keys {
+		assert.Nil(t, b.Insert(key, outputs[i]))
+	}
+	assert.NotNil(t, b.Insert("walk", 1))
+	assert.NotNil(t, b.Insert("abc", 1))
+
+	data := b.Finish()
+	fst := FST{}
+	assert.Nil(t, fst.Init(data))
+	assert.Equal(t, uint(len(keys)), fst.Len())
+
+	for i, key := range keys {
+		output, found := fst.Get(key)
+		assert.True(t, found, key)
+		assert.Equal(t, outputs[i], output, key)
+	}
+	for _, key := range []string{"j", "jum", "jumpe", "pumpeds", "x", "walks"} {
+		_, found := fst.Get(key)
+		assert.False(t, found, key)
+	}
+
+	var got []string
+	var gotOutputs []uint64
+	fst.Iterate("jum", func(key string, output uint64) bool {
+		got = append(got, key)
+		gotOutputs = append(gotOutputs, output)
+		return true
+	})
+	assert.Equal(t, keys[2:5], got)
+	assert.Equal(t, outputs[2:5], gotOutputs)
+
+	got = nil
+	fst.Iterate("", func(key string, output uint64) bool {
+		got = append(got, key)
+		return len(got) < 4
+	})
+	assert.Equal(t, keys[:4], got)
+
+	sections, err := splitSections(data, 2)
+	assert.Nil(t, err)
+	truncated := FST{}
+	assert.NotNil(t, truncated.Init(joinSections(sections[0], sections[1][:len(sections[1])/2])))
+
+	empty := FST{}
+	assert.Nil(t, empty.Init(NewFSTBuilder().Finish()))
+	_, found := empty.Get("")
+	assert.False(t, found)
+}
@@ -0,0 +1,98 @@
+package bits
+
+import "unicode/utf8"
+
+/*
+*
+
+	Returns up to limit words of the trie that are at most maxEdits
+	insertions, deletions or substitutions of characters away from word,
+	in the order the trie stores them. Characters are compared after
+	folding them with the table set with SetFolding, and the words are
+	returned in their original spellings. word is normalized like the keys
+	first.
+*/
+func (f *FrozenTrie) GetFuzzyWords(word string, maxEdits int, limit int) []string {
+	var result []string
+	query := []rune(f.folding.Fold(f.normalizeWord(word)))
+
+	// row[i] is the edit distance between the first i characters of the
+	// query and the characters of the path followed so far. The bytes of
+	// a character are collected from spelling[pending:] until it is
+	// complete.
+	var visit func(p triePosition, spelling []byte, pending int, row []int)
+	visit = func(p triePosition, spelling []byte, pending int, row []int) {
+		if pending < len(spelling) && utf8.FullRune(spelling[pending:]) {
+			r, _ := utf8.DecodeRune(spelling[pending:])
+			row = nextEditRow(row, query, f.folding.foldRune(r))
+			pending = len(spelling)
+
+			// every longer path needs at least as many edits.
+			closest := row[0]
+			for _, edits := range row {
+				if edits < closest {
+					closest = edits
+				}
+			}
+			if closest > maxEdits {
+				return
+			}
+		}
+
+		if pending == len(spelling) && p.final() && row[len(query)] <= maxEdits {
+			result = append(result, string(spelling))
+		}
+		f.forEachStep(p, func(letter byte, next triePosition) {
+			if len(result) < limit {
+				visit(next, append(spelling, letter), pending, row)
+			}
+		})
+	}
+
+	row := make([]int, len(query)+1)
+	for i := range row {
+		row[i] = i
+	}
+	if limit > 0 {
+		visit(triePosition{node: f.GetRoot()}, nil, 0, row)
+	}
+	return result
+}
+
+// Returns the row of edit distances after the characters of row are
+// followed by r.
+func nextEditRow(row []int, query []rune, r rune) []int {
+	next := make([]int, len(row))
+	next[0] = row[0] + 1
+	for i := 1; i < len(row); i++ {
+		substitute := row[i-1]
+		if query[i-1] != r {
+			substitute++
+		}
+		next[i] = substitute
+		if row[i]+1 < next[i] {
+			next[i] = row[i] + 1
+		}
+		if next[i-1]+1 < next[i] {
+			next[i] = next[i-1] + 1
+		}
+	}
+	return next
+}
+
+// Calls fn with each letter leading out of the position, and the position
+// it leads to.
+func (f *FrozenTrie) forEachStep(p triePosition, fn func(letter byte, next triePosition)) {
+	if p.tail != "" {
+		if p.tailPos < len(p.tail) {
+			next := p
+			next.tailPos++
+			fn(p.tail[p.tailPos], next)
+		}
+		return
+	}
+	for i := uint(0); i < p.node.GetChildCount(); i++ {
+		child := p.node.GetChild(i)
+		fn(child.letter, triePosition{node: child, tail: f.getTail(child)})
+	}
+}
@@ -0,0 +1,37 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyWords(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	for _, word := range []string{"dhamma", "dhammā", "dhātu", "kamma", "saṃgha", "saṅgha", "samatha"} {
+		te.Insert(word)
+	}
+	ft := freezeTrie(&te)
+
+	assert.Equal(t, []string{"kamma"}, ft.GetFuzzyWords("kamma", 0, 10))
+	assert.ElementsMatch(t, []string{"dhamma", "kamma"}, ft.GetFuzzyWords("damma", 1, 10))
+	assert.ElementsMatch(t, []string{"dhamma", "dhammā", "kamma"}, ft.GetFuzzyWords("dhamma", 2, 10))
+	assert.Len(t, ft.GetFuzzyWords("dhamma", 2, 2), 2)
+	assert.Empty(t, ft.GetFuzzyWords("dhamma", 2, 0))
+	assert.Empty(t, ft.GetFuzzyWords("xyz", 1, 10))
+
+	// folded characters cost nothing; an edit is a character, not a byte
+	assert.Empty(t, ft.GetFuzzyWords("samgha", 0, 10))
+	assert.ElementsMatch(t, []string{"saṃgha", "saṅgha"}, ft.GetFuzzyWords("samgha", 1, 10))
+	ft.SetFolding(NewFoldingTable(PaliFolding))
+	assert.ElementsMatch(t, []string{"saṃgha"}, ft.GetFuzzyWords("samgha", 0, 10))
+	assert.ElementsMatch(t, []string{"dhamma", "dhammā"}, ft.GetFuzzyWords("dhamma", 0, 10))
+
+	// tails are compared character by character too
+	tailed := freezeTrieWithTails(t, &te)
+	tailed.SetFolding(ft.GetFolding())
+	assert.ElementsMatch(t, []string{"dhātu"}, tailed.GetFuzzyWords("datu", 1, 10))
+	assert.ElementsMatch(t, []string{"saṃgha", "saṅgha", "samatha"}, tailed.GetFuzzyWords("samgha", 2, 10))
+}
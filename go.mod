@@ -2,9 +2,13 @@ module github.com/nicktobey/go-succinct-data-structure-trie
 
 go 1.17
 
+require (
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/text v0.13.0
+)
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.9.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
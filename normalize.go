@@ -0,0 +1,158 @@
+package bits
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+/*
+*
+
+	NormalizationForm names the Unicode normalization applied to keys when
+	they are inserted and to words when they are looked up, so that, say, a
+	followed by a combining macron matches a precomposed ā.
+*/
+type NormalizationForm uint8
+
+const (
+	// Compare raw bytes.
+	NoNormalization NormalizationForm = iota
+	NFC
+	NFD
+	NFKC
+	NFKD
+)
+
+// Returns the form of the norm package, or false for NoNormalization.
+// Returns an error if form is not one of the constants above.
+func (form NormalizationForm) unicodeForm() (norm.Form, bool, error) {
+	switch form {
+	case NoNormalization:
+		return 0, false, nil
+	case NFC:
+		return norm.NFC, true, nil
+	case NFD:
+		return norm.NFD, true, nil
+	case NFKC:
+		return norm.NFKC, true, nil
+	case NFKD:
+		return norm.NFKD, true, nil
+	}
+	return 0, false, fmt.Errorf("normalize: unknown normalization form %d", form)
+}
+
+/*
+*
+
+	normalizer holds the normalization of the keys of a trie. Trie and the
+	frozen encodings embed it, and the encodings record it, so that queries
+	are normalized the way the keys were.
+*/
+type normalizer struct {
+	normalization NormalizationForm
+	// set by FrozenTrieMap.CreateCaseFolded
+	foldCase bool
+}
+
+/*
+*
+
+	Sets the normalization form of the keys. A Trie normalizes the words
+	inserted, so call it after Init and before inserting; a frozen trie
+	normalizes the words looked up, and reads the form it was built with
+	from its encoding. Returns an error for an unknown form.
+*/
+func (n *normalizer) SetNormalization(form NormalizationForm) error {
+	if _, _, err := form.unicodeForm(); err != nil {
+		return err
+	}
+	n.normalization = form
+	return nil
+}
+
+/*
+*
+
+	Returns the normalization form of the keys.
+*/
+func (n *normalizer) GetNormalization() NormalizationForm {
+	return n.normalization
+}
+
+// Returns word in the normalization form of the keys, with the full Unicode
+// case folding applied if the keys are folded, so that STRASSE matches
+// straße and a final sigma matches σ.
+func (n *normalizer) normalizeWord(word string) string {
+	// the form was checked when it was set or read.
+	form, ok, _ := n.normalization.unicodeForm()
+	if ok {
+		word = form.String(word)
+	}
+	if n.foldCase {
+		// a Caser keeps state, so it cannot be shared between goroutines.
+		word = cases.Fold().String(word)
+		// folding can undo the normalization: ΐ folds to ι and two
+		// combining marks.
+		if ok {
+			word = form.String(word)
+		}
+	}
+	return word
+}
+
+// Returns r, normalized like the keys as it is read.
+func (n *normalizer) normalizeReader(r io.Reader) io.Reader {
+	if form, ok, _ := n.normalization.unicodeForm(); ok {
+		return form.Reader(r)
+	}
+	return r
+}
+
+// The flags written by writeHeader after the form.
+const foldCaseFlag = 1
+
+// Writes the normalization form in 8 bits, then 8 bits of flags.
+func (n *normalizer) writeHeader(bw *BitWriter) {
+	bw.Write(uint(n.normalization), 8)
+	if n.foldCase {
+		bw.Write(foldCaseFlag, 8)
+	} else {
+		bw.Write(0, 8)
+	}
+}
+
+// Reads the normalization form and flags written by writeHeader at p.
+func (n *normalizer) readHeader(bs *BitString, p uint) error {
+	if err := n.SetNormalization(NormalizationForm(bs.Get(p, 8))); err != nil {
+		return err
+	}
+	n.foldCase = bs.Get(p+8, 8)&foldCaseFlag != 0
+	return nil
+}
+
+// Writes the header after the letters of an encoded trie, at the next byte
+// boundary, unless the keys are neither normalized nor case folded, so that
+// the encoding of such tries is unchanged.
+func (n *normalizer) writeTrailer(bw *BitWriter) {
+	if *n == (normalizer{}) {
+		return
+	}
+	if pad := bw.Len() % W; pad > 0 {
+		bw.Write(0, W-pad)
+	}
+	n.writeHeader(bw)
+}
+
+// Reads the header written by writeTrailer after the letters, which end
+// at bit end of data, or clears it if there is none.
+func (n *normalizer) readTrailer(data *BitString, end uint) error {
+	*n = normalizer{}
+	p := (end + W - 1) / W * W
+	if data.length < p+16 {
+		return nil
+	}
+	return n.readHeader(data, p)
+}
@@ -0,0 +1,142 @@
+package bits
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestNormalization(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	assert.Nil(t, te.SetNormalization(NFC))
+	assert.Equal(t, NFC, te.GetNormalization())
+	// decomposed spellings
+	te.Insert("dha\u0304tu")
+	te.Insert("nibba\u0304na")
+	te.Insert("pi\u0304ti")
+
+	teData, _ := te.Encode()
+	ftm := FrozenTrieMap{}
+	assert.Nil(t, ftm.Create(teData, te.GetNodeCount()))
+	assert.Equal(t, NFC, ftm.Ft.GetNormalization())
+
+	restored := FrozenTrieMap{}
+	assert.Nil(t, restored.InitFromData(ftm.GetData()))
+	assert.Equal(t, NFC, restored.Ft.GetNormalization())
+	for _, m := range []*FrozenTrieMap{&ftm, &restored} {
+		assert.True(t, m.Ft.Lookup("dhātu"))
+		assert.True(t, m.Ft.Lookup("dha\u0304tu"))
+		index, found := m.LookupIndex("nibba\u0304na")
+		assert.True(t, found)
+		assert.Equal(t, "nibbāna", m.ReverseLookup(index))
+		assert.Equal(t, []string{"pīti"}, m.Ft.GetSuggestedWords("pi\u0304", 10))
+		m.Ft.SetFolding(NewFoldingTable(PaliFolding))
+		assert.True(t, m.Ft.Lookup("piti"))
+		assert.True(t, m.Ft.Lookup("pi\u0304ti"))
+	}
+
+	decomposed := Trie{}
+	decomposed.Init()
+	assert.Nil(t, decomposed.SetNormalization(NFD))
+	decomposed.Insert("dhātu")
+	ft := freezeTrie(&decomposed)
+	assert.Equal(t, NFD, ft.GetNormalization())
+	assert.True(t, ft.Lookup("dhātu"))
+	assert.True(t, ft.Lookup("dha\u0304tu"))
+	assert.Equal(t, []string{"dha\u0304tu"}, ft.GetSuggestedWords("dhā", 10))
+
+	// an unknown form is an error, on Trie and when reading an encoding
+	assert.NotNil(t, te.SetNormalization(NFKD+1))
+	assert.Equal(t, NFC, te.GetNormalization())
+	te.normalization = NFKD + 1
+	teData, _ = te.Encode()
+	assert.NotNil(t, ftm.Create(teData, te.GetNodeCount()))
+	rd := CreateRankDirectory(teData, te.GetNodeCount()*2+1, L1, L2)
+	assert.NotNil(t, ft.Init(teData, rd.GetData(), te.GetNodeCount()))
+	for _, topology := range []Topology{LOUDSTopology, BPTopology, TailTopology, PatriciaTopology, DAWGTopology, FastTopology} {
+		_, err := te.Freeze(topology)
+		assert.NotNil(t, err, topology)
+	}
+	assert.Nil(t, te.SetNormalization(NoNormalization))
+}
+
+func TestNormalizationEncodings(t *testing.T) {
+	useByteLetters(t)
+	spellings := []string{"dhātu", "nibbāna", "pīti", "pītika"}
+	te := Trie{}
+	te.Init()
+	assert.Nil(t, te.SetNormalization(NFC))
+	for _, word := range spellings {
+		te.Insert(norm.NFD.String(word))
+	}
+
+	topologies := []Topology{LOUDSTopology, BPTopology, TailTopology, PatriciaTopology, DAWGTopology, FastTopology}
+	for _, topology := range topologies {
+		dict, err := te.Freeze(topology)
+		assert.Nil(t, err)
+		assert.Equal(t, NFC, dict.GetNormalization(), topology)
+		assert.True(t, dict.Lookup("dhātu"), topology)
+		assert.True(t, dict.Lookup("dha\u0304tu"), topology)
+		assert.False(t, dict.Lookup("dhatu"), topology)
+		suggested := dict.GetSuggestedWords("pi\u0304", 10)
+		sort.Strings(suggested)
+		assert.Equal(t, []string{"pīti", "pītika"}, suggested, topology)
+	}
+
+	builder := NewFSTBuilder()
+	assert.Nil(t, builder.SetNormalization(NFC))
+	for i, word := range spellings {
+		assert.Nil(t, builder.Insert(norm.NFD.String(word), uint64(i)))
+	}
+	assert.NotNil(t, builder.Insert("pītika", 9))
+	fst := FST{}
+	assert.Nil(t, fst.Init(builder.Finish()))
+	assert.Equal(t, NFC, fst.GetNormalization())
+	output, found := fst.Get("nibba\u0304na")
+	assert.True(t, found)
+	assert.Equal(t, uint64(1), output)
+	var keys []string
+	fst.Iterate("pi\u0304", func(key string, output uint64) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []string{"pīti", "pītika"}, keys)
+
+	built, err := CreateRangeFilter(spellings, NFD, RealSuffix, 8)
+	assert.Nil(t, err)
+	rf := RangeFilter{}
+	assert.Nil(t, rf.Init(built.GetData()))
+	for _, filter := range []*RangeFilter{&built, &rf} {
+		assert.True(t, filter.MayContain("nibba\u0304na"))
+		assert.True(t, filter.MayContainRange("pīt", "pītz"))
+		assert.False(t, filter.MayContainRange("a", "b"))
+	}
+	_, err = CreateRangeFilter(spellings, NFKD+1, NoSuffix, 0)
+	assert.NotNil(t, err)
+
+	ftm := createTestMap(&te)
+	ac, err := CreateAhoCorasick(&ftm)
+	assert.Nil(t, err)
+	text := "xdha\u0304tu pīti"
+	matches := ac.FindAll(text)
+	if assert.Len(t, matches, 2) {
+		assert.Equal(t, uint(1), matches[0].Start)
+		assert.Equal(t, "dhātu", ftm.ReverseLookup(matches[0].KeyIndex))
+		assert.Equal(t, "pīti", norm.NFC.String(text)[matches[1].Start:matches[1].End])
+	}
+	var scanned []Match
+	assert.Nil(t, ac.Scan(strings.NewReader(text), func(m Match) {
+		scanned = append(scanned, m)
+	}))
+	assert.Equal(t, matches, scanned)
+
+	segmentation, found := NewMapSegmenter(&ftm).Best("dha\u0304tupīti")
+	assert.True(t, found)
+	assert.Equal(t, []string{"dhātu", "pīti"}, words(segmentation))
+	assert.Equal(t, []string{"pīti", "pītika"}, ftm.Ft.GetFuzzyWords("pi\u0304tik", 1, 10))
+}
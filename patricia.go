@@ -0,0 +1,248 @@
+package bits
+
+import "strings"
+
+/*
+*
+
+	FrozenPatriciaTrie is a path-compressed trie: every chain of non-final
+	nodes with a single child is merged into one edge carrying a label
+	string, so only final and branching nodes remain in the LOUDS topology.
+
+	The compressed topology is stored like a FrozenTrie, with the first byte
+	of each edge label as the letter of its node. The rest of the labels are
+	concatenated into a string pool, with their start offsets in an
+	EliasFano sequence indexed by node.
+*/
+type FrozenPatriciaTrie struct {
+	trie    FrozenTrie
+	offsets EliasFano
+	pool    string
+	foldable
+}
+
+/*
+*
+
+	Encode the trie with path compression. The compressed topology is
+	indexed by a RankSelect of the given kind. Returns a string to be read
+	with FrozenPatriciaTrie.Init.
+*/
+func (t *Trie) EncodePatricia(kind RankSelectKind) string {
+	labels := map[*TrieNode]string{}
+	compressed := Trie{root: compressPaths(t.root, labels), normalizer: t.normalizer}
+
+	var offsets []uint64
+	var pool strings.Builder
+	compressed.Apply(func(node *TrieNode) {
+		compressed.nodeCount++
+		offsets = append(offsets, uint64(pool.Len()))
+		pool.WriteString(labels[node])
+	})
+	offsets = append(offsets, uint64(pool.Len()))
+
+	// offsets are non-decreasing, so this cannot fail.
+	ef, _ := CreateEliasFano(offsets)
+	return joinSections(compressed.EncodeLOUDS(kind, false), ef.GetData(), pool.String())
+}
+
+// Copies the trie below node, merging single child chains into the node at
+// their end. labels receives the letters of each merged edge after the
+// first.
+func compressPaths(node *TrieNode, labels map[*TrieNode]string) *TrieNode {
+	result := &TrieNode{letter: node.letter, final: node.final}
+	for _, child := range node.children {
+		var label []byte
+		end := child
+		for len(end.children) == 1 && !end.final {
+			end = end.children[0]
+			label = append(label, end.letter)
+		}
+
+		compressed := compressPaths(end, labels)
+		compressed.letter = child.letter
+		if len(label) > 0 {
+			labels[compressed] = string(label)
+		}
+		result.children = append(result.children, compressed)
+	}
+	return result
+}
+
+/*
+*
+
+	Initializes the trie from the string returned by Trie.EncodePatricia.
+*/
+func (f *FrozenPatriciaTrie) Init(data string) error {
+	sections, err := splitSections(data, 3)
+	if err != nil {
+		return err
+	}
+	if err := f.trie.InitFromData(sections[0]); err != nil {
+		return err
+	}
+	if err := f.offsets.Init(sections[1]); err != nil {
+		return err
+	}
+	f.pool = sections[2]
+	return nil
+}
+
+/*
+*
+
+	Returns the normalization form of the keys, read from the encoding.
+*/
+func (f *FrozenPatriciaTrie) GetNormalization() NormalizationForm {
+	return f.trie.GetNormalization()
+}
+
+/*
+*
+
+	Returns the label of the edge leading into the node.
+*/
+func (f *FrozenPatriciaTrie) getLabel(node FrozenTrieNode) string {
+	start := f.offsets.Get(node.index)
+	end := f.offsets.Get(node.index + 1)
+	return string([]byte{node.letter}) + f.pool[start:end]
+}
+
+/*
+*
+
+	A position in the compressed trie: a node, and the bytes of the label of
+	the edge into it matched so far.
+*/
+type patriciaPosition struct {
+	node     FrozenTrieNode
+	label    string
+	labelPos int
+}
+
+// Returns the position reached from p by the letter.
+func (f *FrozenPatriciaTrie) step(p patriciaPosition, letter byte) (patriciaPosition, bool) {
+	if p.labelPos < len(p.label) {
+		if p.label[p.labelPos] != letter {
+			return p, false
+		}
+		p.labelPos++
+		return p, true
+	}
+	child, found := f.trie.child(p.node, letter)
+	if !found {
+		return p, false
+	}
+	return patriciaPosition{child, f.getLabel(child), 1}, true
+}
+
+// A position at the end of a spelling of the word passed to descend.
+type patriciaDescent struct {
+	position patriciaPosition
+	spelling string
+}
+
+/*
+*
+
+	Follows word, once normalized like the keys, down from the root, or with
+	a folding table, every spelling that folds like it, the exact one first. If a spelling ends inside an
+	edge, its position holds the node below that edge.
+*/
+func (f *FrozenPatriciaTrie) descend(word string) []patriciaDescent {
+	var result []patriciaDescent
+	word = f.trie.normalizeWord(word)
+	positions := []patriciaPosition{{node: f.trie.GetRoot()}}
+	f.folding.expand(word, 0, func(from int, letter byte) (int, bool) {
+		next, found := f.step(positions[from], letter)
+		if !found {
+			return 0, false
+		}
+		positions = append(positions, next)
+		return len(positions) - 1, true
+	}, func(at int, spelling string, covered int) {
+		if covered == len(word) {
+			result = append(result, patriciaDescent{positions[at], spelling})
+		}
+	})
+	return result
+}
+
+/*
+*
+
+	Look-up a word in the trie. Returns true if and only if the word exists
+	in the trie.
+*/
+func (f *FrozenPatriciaTrie) Lookup(word string) bool {
+	_, found := f.LookupIndex(word)
+	return found
+}
+
+/*
+*
+
+	Returns the level-order index of the node of the word among the nodes of
+	the compressed trie.
+*/
+func (f *FrozenPatriciaTrie) LookupIndex(word string) (index uint, found bool) {
+	for _, match := range f.descend(word) {
+		p := match.position
+		if p.labelPos == len(p.label) && p.node.final {
+			return p.node.index, true
+		}
+	}
+	return 0, false
+}
+
+/*
+*
+
+	Given a word, returns array of words, prefix of which is word. See
+	FrozenTrie.GetSuggestedWords.
+*/
+func (f *FrozenPatriciaTrie) GetSuggestedWords(word string, limit int) []string {
+	var result []string
+	for _, match := range f.descend(word) {
+		if len(result) > limit {
+			break
+		}
+		p := match.position
+		result = append(result, f.traverseSubTrie(p.node, match.spelling+p.label[p.labelPos:], limit-len(result))...)
+	}
+	return result
+}
+
+func (f *FrozenPatriciaTrie) traverseSubTrie(node FrozenTrieNode, prefix string, limit int) []string {
+	var result []string
+
+	var level []FrozenTrieNode
+	level = append(level, node)
+	var prefixLevel []string
+	prefixLevel = append(prefixLevel, prefix)
+
+	for len(level) > 0 {
+		nodeNow := level[0]
+		level = level[1:]
+		prefixNow := prefixLevel[0]
+		prefixLevel = prefixLevel[1:]
+
+		// if the prefix is a legal word.
+		if nodeNow.final {
+			result = append(result, prefixNow)
+			if len(result) > limit {
+				return result
+			}
+		}
+
+		var i uint = 0
+		for ; i < nodeNow.GetChildCount(); i++ {
+			child := nodeNow.GetChild(i)
+			level = append(level, child)
+			prefixLevel = append(prefixLevel, prefixNow+f.getLabel(child))
+		}
+	}
+
+	return result
+}
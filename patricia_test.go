@@ -0,0 +1,45 @@
+package bits
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatricia(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertNotInAlphabeticalOrder(&te)
+	te.Insert("app")
+	te.Insert("applesauce")
+	te.Insert("applesauces")
+
+	ft := freezeTrie(&te)
+	dicts := []Dictionary{ft}
+	for _, kind := range []RankSelectKind{RankDirectoryKind, PlainKind, RRRKind} {
+		fpt := FrozenPatriciaTrie{}
+		assert.Nil(t, fpt.Init(te.EncodePatricia(kind)))
+		assert.Less(t, fpt.trie.GetNodeCount(), te.GetNodeCount())
+		assert.Equal(t, kind, fpt.trie.GetDirectory().Kind())
+		dicts = append(dicts, &fpt)
+	}
+
+	for _, dict := range dicts {
+		for _, word := range []string{"alphapha", "app", "apple", "applesauce", "applesauces", "hello", "jello", "lamp", "orange", "quiz"} {
+			assert.True(t, dict.Lookup(word), word)
+		}
+		for _, word := range []string{"", "a", "ap", "appl", "applesauc", "hell", "helloo", "quizz", "z"} {
+			assert.False(t, dict.Lookup(word), word)
+		}
+
+		for _, prefix := range []string{"", "a", "app", "apples", "h", "hx", "z"} {
+			suggested := dict.GetSuggestedWords(prefix, 20)
+			sort.Strings(suggested)
+			expected := ft.GetSuggestedWords(prefix, 20)
+			sort.Strings(expected)
+			assert.Equal(t, expected, suggested, prefix)
+		}
+	}
+}
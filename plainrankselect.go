@@ -0,0 +1,198 @@
+package bits
+
+/*
+*
+
+	The number of bits summarized by each rank sample of a PlainRankSelect.
+*/
+const PlainSampleSize uint = 64
+
+/*
+*
+
+	PlainRankSelect keeps the bits uncompressed next to a dense table of
+	samples: for every PlainSampleSize bits, the number of 1 bits before
+	them. Rank needs one sample and one short count; select does a binary
+	search over the samples. It is larger than the RankDirectory but has a
+	single level, so it is simpler and faster to query.
+*/
+type PlainRankSelect struct {
+	data        BitString
+	samples     BitString
+	sampleWidth uint
+	numBits     uint
+}
+
+/*
+*
+
+	Builds the sample table over the first numBits bits of data.
+*/
+func CreatePlainRankSelect(data string, numBits uint) PlainRankSelect {
+	return buildPlainRankSelect(data, numBits, BitWriter{}, 0, 0)
+}
+
+// Writes the samples from bit p on, a multiple of PlainSampleSize, after
+// those already in samples. count is the number of 1 bits before p.
+func buildPlainRankSelect(data string, numBits uint, samples BitWriter, p, count uint) PlainRankSelect {
+	bits := BitString{}
+	bits.Init(data)
+	sampleWidth := getOffsetBits(numBits)
+
+	for ; p < numBits; p += PlainSampleSize {
+		samples.Write(count, sampleWidth)
+		n := PlainSampleSize
+		if numBits-p < n {
+			n = numBits - p
+		}
+		count += bits.Count(p, n)
+	}
+
+	prs := PlainRankSelect{}
+	prs.Init(samples.GetData(), data, numBits)
+	return prs
+}
+
+// Builds the sample table over the first numBits bits of data, whose first
+// unchanged bits are those prs indexes, copying the samples of the blocks
+// before them if their width is the same.
+func (prs *PlainRankSelect) extend(data string, numBits, unchanged uint) PlainRankSelect {
+	var kept uint = 0
+	if getOffsetBits(numBits) == prs.sampleWidth {
+		kept = unchanged / PlainSampleSize
+		if kept > 0 && kept >= prs.numSamples() {
+			kept = prs.numSamples() - 1
+		}
+	}
+	samples := BitWriter{}
+	samples.WriteFrom(&prs.samples, 0, kept*prs.sampleWidth)
+	var count uint = 0
+	if kept > 0 {
+		count = prs.sample(kept)
+	}
+	return buildPlainRankSelect(data, numBits, samples, kept*PlainSampleSize, count)
+}
+
+/*
+*
+
+	Restores the structure from the sample table returned by GetData and
+	the bits it indexes.
+*/
+func (prs *PlainRankSelect) Init(sampleData, data string, numBits uint) {
+	prs.data.Init(data)
+	prs.samples.Init(sampleData)
+	prs.sampleWidth = getOffsetBits(numBits)
+	prs.numBits = numBits
+}
+
+/*
+*
+
+	Returns the encoded sample table.
+*/
+func (prs *PlainRankSelect) GetData() string {
+	return prs.samples.GetData()
+}
+
+func (prs *PlainRankSelect) Kind() RankSelectKind {
+	return PlainKind
+}
+
+/*
+*
+
+	Returns the number of bits indexed.
+*/
+func (prs *PlainRankSelect) Len() uint {
+	return prs.numBits
+}
+
+/*
+*
+
+	Returns the size of the bits plus the sample table, in bits.
+*/
+func (prs *PlainRankSelect) SizeInBits() uint {
+	return prs.numBits + prs.numSamples()*prs.sampleWidth
+}
+
+func (prs *PlainRankSelect) numSamples() uint {
+	return (prs.numBits + PlainSampleSize - 1) / PlainSampleSize
+}
+
+// number of 1 bits before sample s
+func (prs *PlainRankSelect) sample(s uint) uint {
+	return prs.samples.Get(s*prs.sampleWidth, prs.sampleWidth)
+}
+
+/*
+*
+
+	Returns the number of 1 bits up to and including position x.
+*/
+func (prs *PlainRankSelect) Rank1(x uint) uint {
+	s := x / PlainSampleSize
+	return prs.sample(s) + prs.data.Count(s*PlainSampleSize, x%PlainSampleSize+1)
+}
+
+/*
+*
+
+	Returns the number of 0 bits up to and including position x.
+*/
+func (prs *PlainRankSelect) Rank0(x uint) uint {
+	return x + 1 - prs.Rank1(x)
+}
+
+/*
+*
+
+	Returns the position of the y'th 1 bit.
+*/
+func (prs *PlainRankSelect) Select1(y uint) uint {
+	return prs.selectBit(1, y)
+}
+
+/*
+*
+
+	Returns the position of the y'th 0 bit.
+*/
+func (prs *PlainRankSelect) Select0(y uint) uint {
+	return prs.selectBit(0, y)
+}
+
+func (prs *PlainRankSelect) selectBit(which, y uint) uint {
+	before := func(s uint) uint {
+		if which == 1 {
+			return prs.sample(s)
+		}
+		return s*PlainSampleSize - prs.sample(s)
+	}
+
+	// find the last sample with fewer than y matching bits before it.
+	low, high := uint(0), prs.numSamples()
+	for high-low > 1 {
+		probe := (low + high) / 2
+		if before(probe) < y {
+			low = probe
+		} else {
+			high = probe
+		}
+	}
+
+	count := before(low)
+	for p := low * PlainSampleSize; p < prs.numBits; p++ {
+		if prs.data.Get(p, 1) == which {
+			count++
+			if count == y {
+				return p
+			}
+		}
+	}
+
+	// like RankDirectory, return -1 converted to uint when there is no
+	// such bit.
+	return ^uint(0)
+}
@@ -0,0 +1,264 @@
+package bits
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+/*
+*
+
+	Postings stores a sorted list of document IDs for every key of a
+	FrozenTrieMap, so the trie can serve as the dictionary of an inverted
+	index. Each list is delta-encoded and written with a variable-byte code:
+	7 bits of payload per byte, the high bit set on every byte but the last.
+
+	The lists are concatenated in key index order. A fixed-width table of
+	byte offsets, one entry per key plus a final end offset, gives random
+	access to the list of any key.
+*/
+type Postings struct {
+	data       BitString
+	offsets    BitString
+	offsetBits uint
+	numKeys    uint
+}
+
+/*
+*
+
+	Builds the postings for the given map. Every word in lists must be a key
+	of ftm, and every list must be sorted in strictly increasing order. Keys
+	without an entry in lists get an empty list.
+*/
+func CreatePostings(ftm *FrozenTrieMap, lists map[string][]uint64) (Postings, error) {
+	numKeys := ftm.GetKeyCount()
+	byKey := make([][]uint64, numKeys+1)
+
+	for word, docIDs := range lists {
+		index, found := ftm.LookupIndex(word)
+		if !found {
+			return Postings{}, fmt.Errorf("postings: %q is not a key of the trie", word)
+		}
+		for i := 1; i < len(docIDs); i++ {
+			if docIDs[i] <= docIDs[i-1] {
+				return Postings{}, fmt.Errorf("postings: list of %q is not strictly increasing at position %d", word, i)
+			}
+		}
+		byKey[index] = docIDs
+	}
+
+	var data bytes.Buffer
+	ends := make([]uint, 0, numKeys+1)
+	ends = append(ends, 0)
+	for index := uint(1); index <= numKeys; index++ {
+		var previous uint64
+		for _, docID := range byKey[index] {
+			writeVByte(&data, docID-previous)
+			previous = docID
+		}
+		ends = append(ends, uint(data.Len()))
+	}
+
+	offsetBits := getOffsetBits(uint(data.Len()))
+	offsets := BitWriter{}
+	for _, end := range ends {
+		offsets.Write(end, offsetBits)
+	}
+
+	p := Postings{}
+	p.Init(data.String(), offsets.GetData(), numKeys)
+	return p, nil
+}
+
+/*
+*
+
+	Initializes the postings from the data previously returned by GetData
+	and GetOffsets.
+
+	@param numKeys The number of keys in the FrozenTrieMap.
+*/
+func (p *Postings) Init(data, offsetData string, numKeys uint) {
+	p.data.Init(data)
+	p.offsets.Init(offsetData)
+	p.offsetBits = getOffsetBits(uint(len(data)))
+	p.numKeys = numKeys
+}
+
+/*
+*
+
+	Returns the encoded postings lists.
+*/
+func (p *Postings) GetData() string {
+	return p.data.GetData()
+}
+
+/*
+*
+
+	Returns the encoded offset table.
+*/
+func (p *Postings) GetOffsets() string {
+	return p.offsets.GetData()
+}
+
+/*
+*
+
+	Returns an iterator over the postings list of the key with the given
+	index, as returned by FrozenTrieMap.LookupIndex. Indices outside the map
+	give an empty iterator.
+*/
+func (p *Postings) Iterator(keyIndex uint) PostingsIterator {
+	if keyIndex == 0 || keyIndex > p.numKeys {
+		return PostingsIterator{postings: p}
+	}
+	return PostingsIterator{
+		postings: p,
+		pos:      p.offsets.Get((keyIndex-1)*p.offsetBits, p.offsetBits),
+		end:      p.offsets.Get(keyIndex*p.offsetBits, p.offsetBits),
+	}
+}
+
+/*
+*
+
+	Looks up the word in the map and returns an iterator over its postings.
+	The second result is false if the word is not a key of the map.
+*/
+func (p *Postings) Lookup(ftm *FrozenTrieMap, word string) (PostingsIterator, bool) {
+	index, found := ftm.LookupIndex(word)
+	if !found {
+		return PostingsIterator{postings: p}, false
+	}
+	return p.Iterator(index), true
+}
+
+/*
+*
+
+	PostingsIterator walks one postings list in increasing order. It starts
+	positioned before the first document ID; call Next or Advance to move it.
+*/
+type PostingsIterator struct {
+	postings *Postings
+	pos      uint
+	end      uint
+	docID    uint64
+	valid    bool
+}
+
+/*
+*
+
+	Moves to the next document ID. Returns false when the list is exhausted.
+*/
+func (it *PostingsIterator) Next() bool {
+	if it.pos >= it.end {
+		it.valid = false
+		return false
+	}
+	var delta uint64
+	delta, it.pos = readVByte(&it.postings.data, it.pos)
+	it.docID += delta
+	it.valid = true
+	return true
+}
+
+/*
+*
+
+	Moves to the first document ID greater than or equal to docID, which
+	may be the current one. Returns false when there is no such ID.
+*/
+func (it *PostingsIterator) Advance(docID uint64) bool {
+	if it.valid && it.docID >= docID {
+		return true
+	}
+	for it.Next() {
+		if it.docID >= docID {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+*
+
+	Returns the current document ID.
+*/
+func (it *PostingsIterator) DocID() uint64 {
+	return it.docID
+}
+
+/*
+*
+
+	Returns the document IDs present in every one of the lists. The iterators
+	are consumed.
+*/
+func Intersect(its ...*PostingsIterator) []uint64 {
+	var result []uint64
+	if len(its) == 0 {
+		return result
+	}
+
+	if !its[0].Next() {
+		return result
+	}
+	candidate := its[0].DocID()
+	for {
+		matched := true
+		for _, it := range its {
+			if !it.Advance(candidate) {
+				return result
+			}
+			if it.DocID() > candidate {
+				candidate = it.DocID()
+				matched = false
+				break
+			}
+		}
+		if matched {
+			result = append(result, candidate)
+			if candidate == math.MaxUint64 {
+				return result
+			}
+			candidate++
+		}
+	}
+}
+
+func writeVByte(buf *bytes.Buffer, value uint64) {
+	for value >= 0x80 {
+		buf.WriteByte(byte(value) | 0x80)
+		value >>= 7
+	}
+	buf.WriteByte(byte(value))
+}
+
+func readVByte(bs *BitString, pos uint) (value uint64, next uint) {
+	var shift uint
+	for {
+		b := bs.Get(pos*W, W)
+		pos++
+		value |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return value, pos
+		}
+		shift += 7
+	}
+}
+
+// number of bits needed to store offsets from 0 to maxOffset
+func getOffsetBits(maxOffset uint) uint {
+	var i uint = 1
+	for (1 << i) <= maxOffset {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,54 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostings(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertInAlphabeticalOrder(&te)
+	ftm := createTestMap(&te)
+
+	p, err := CreatePostings(&ftm, map[string][]uint64{
+		"apple":  {1, 5, 200, 70000},
+		"hello":  {5, 7, 200},
+		"orange": {2, 5, 199, 200, 70000, 1 << 40},
+	})
+	assert.Nil(t, err)
+
+	loaded := Postings{}
+	loaded.Init(p.GetData(), p.GetOffsets(), ftm.GetKeyCount())
+
+	var docIDs []uint64
+	it, found := loaded.Lookup(&ftm, "orange")
+	assert.True(t, found)
+	for it.Next() {
+		docIDs = append(docIDs, it.DocID())
+	}
+	assert.Equal(t, []uint64{2, 5, 199, 200, 70000, 1 << 40}, docIDs)
+
+	it, found = loaded.Lookup(&ftm, "quiz")
+	assert.True(t, found)
+	assert.False(t, it.Next())
+
+	it, _ = loaded.Lookup(&ftm, "apple")
+	assert.True(t, it.Advance(6))
+	assert.Equal(t, uint64(200), it.DocID())
+	assert.True(t, it.Advance(200))
+	assert.Equal(t, uint64(200), it.DocID())
+	assert.False(t, it.Advance(70001))
+
+	a, _ := loaded.Lookup(&ftm, "apple")
+	h, _ := loaded.Lookup(&ftm, "hello")
+	o, _ := loaded.Lookup(&ftm, "orange")
+	assert.Equal(t, []uint64{5, 200}, Intersect(&a, &h, &o))
+
+	_, err = CreatePostings(&ftm, map[string][]uint64{"appl": {1}})
+	assert.NotNil(t, err)
+	_, err = CreatePostings(&ftm, map[string][]uint64{"apple": {3, 3}})
+	assert.NotNil(t, err)
+}
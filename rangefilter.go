@@ -0,0 +1,277 @@
+package bits
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+/*
+*
+
+	SuffixType chooses what a RangeFilter stores after the truncated keys.
+*/
+type SuffixType uint8
+
+const (
+	// Store nothing after the minimal distinguishing prefix.
+	NoSuffix SuffixType = iota
+	// Store bits of a hash of the whole key. Lowers the false positive
+	// rate of MayContain only.
+	HashSuffix
+	// Store the bits of the key that follow the prefix. Lowers the false
+	// positive rate of both MayContain and MayContainRange.
+	RealSuffix
+)
+
+/*
+*
+
+	RangeFilter is a succinct range filter in the style of SuRF. It keeps
+	only the shortest prefix of each key that tells it apart from its
+	neighbours in sorted order, in a FrozenTrieMap, plus a few suffix bits
+	per key indexed by key index. It answers whether a key, or any key in a
+	range, may have been inserted: false answers are always right, true
+	answers are false positives at a rate set by the number of suffix bits.
+*/
+type RangeFilter struct {
+	ftm        FrozenTrieMap
+	suffixes   BitString
+	suffixType SuffixType
+	suffixBits uint
+}
+
+/*
+*
+
+	Builds a filter over the keys, normalized to the given form, with
+	suffixBits bits (at most 64) of the given type stored per key. The keys
+	must be sorted and distinct once normalized. Queries are normalized to
+	the same form, which GetData records.
+*/
+func CreateRangeFilter(keys []string, form NormalizationForm, suffixType SuffixType, suffixBits uint) (RangeFilter, error) {
+	if suffixType == NoSuffix {
+		suffixBits = 0
+	}
+	if suffixBits > 64 {
+		return RangeFilter{}, fmt.Errorf("rangefilter: %d suffix bits is more than 64", suffixBits)
+	}
+	n := normalizer{}
+	if err := n.SetNormalization(form); err != nil {
+		return RangeFilter{}, err
+	}
+	normalized := make([]string, len(keys))
+	for i, key := range keys {
+		normalized[i] = n.normalizeWord(key)
+	}
+	keys = normalized
+
+	commonPrefix := func(a, b string) int {
+		i := 0
+		for i < len(a) && i < len(b) && a[i] == b[i] {
+			i++
+		}
+		return i
+	}
+
+	// the minimal distinguishing prefix is one byte longer than the longest
+	// prefix shared with a neighbour.
+	prefixes := make([]string, len(keys))
+	for i, key := range keys {
+		length := 0
+		if i > 0 {
+			if keys[i-1] >= key {
+				return RangeFilter{}, fmt.Errorf("rangefilter: key %q follows %q", key, keys[i-1])
+			}
+			length = commonPrefix(keys[i-1], key)
+		}
+		if i+1 < len(keys) {
+			if next := commonPrefix(key, keys[i+1]); next > length {
+				length = next
+			}
+		}
+		if length < len(key) {
+			length++
+		}
+		prefixes[i] = key[:length]
+	}
+
+	// the prefixes may end inside a character, so they are inserted and
+	// looked up as they are, and the form is set once the map is built.
+	te := Trie{}
+	te.Init()
+	for _, prefix := range prefixes {
+		te.Insert(prefix)
+	}
+	teData, _ := te.Encode()
+
+	rf := RangeFilter{suffixType: suffixType, suffixBits: suffixBits}
+	rf.ftm.Create(teData, te.GetNodeCount())
+
+	suffixes := make([]uint, len(keys)+1)
+	for i, key := range keys {
+		index, _ := rf.ftm.LookupIndex(prefixes[i])
+		suffixes[index] = rf.getSuffix(key, uint(len(prefixes[i])))
+	}
+	rf.ftm.Ft.normalizer = n
+	bw := BitWriter{}
+	for _, suffix := range suffixes[1:] {
+		bw.Write(suffix, suffixBits)
+	}
+	rf.suffixes.Init(bw.GetData())
+	return rf, nil
+}
+
+/*
+*
+
+	Returns the filter encoded as a single string.
+*/
+func (rf *RangeFilter) GetData() string {
+	header := BitWriter{}
+	header.Write(uint(rf.suffixType), 8)
+	header.Write(rf.suffixBits, 8)
+	return joinSections(header.GetData(), rf.ftm.GetData(), rf.suffixes.GetData())
+}
+
+/*
+*
+
+	Restores a filter from the string returned by GetData.
+*/
+func (rf *RangeFilter) Init(data string) error {
+	sections, err := splitSections(data, 3)
+	if err != nil {
+		return err
+	}
+	if len(sections[0]) < 2 {
+		return fmt.Errorf("rangefilter: header too short")
+	}
+	rf.suffixType = SuffixType(sections[0][0])
+	rf.suffixBits = uint(sections[0][1])
+	rf.suffixes.Init(sections[2])
+	return rf.ftm.InitFromData(sections[1])
+}
+
+// the suffix bits of key, whose first length bytes are in the trie
+func (rf *RangeFilter) getSuffix(key string, length uint) uint {
+	switch rf.suffixType {
+	case HashSuffix:
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		return uint(h.Sum64()) & (1<<rf.suffixBits - 1)
+	case RealSuffix:
+		var suffix uint = 0
+		for i := uint(0); i*W < rf.suffixBits; i++ {
+			var b uint = 0
+			if length+i < uint(len(key)) {
+				b = uint(key[length+i])
+			}
+			suffix = suffix<<W | b
+		}
+		// drop the bits of the last byte beyond suffixBits
+		return suffix >> ((W - rf.suffixBits%W) % W)
+	}
+	return 0
+}
+
+func (rf *RangeFilter) storedSuffix(node FrozenTrieNode) uint {
+	index := rf.ftm.keys.Rank1(node.index)
+	return rf.suffixes.Get((index-1)*rf.suffixBits, rf.suffixBits)
+}
+
+/*
+*
+
+	Returns false if the key was certainly not inserted.
+*/
+func (rf *RangeFilter) MayContain(key string) bool {
+	key = rf.ftm.Ft.normalizeWord(key)
+	node := rf.ftm.Ft.GetRoot()
+	for i := 0; i <= len(key); i++ {
+		// a final leaf holds a truncated key, which matches any key
+		// starting with it, as far as the suffix can tell.
+		if node.final && node.childCount == 0 {
+			return rf.storedSuffix(node) == rf.getSuffix(key, uint(i))
+		}
+		if i == len(key) {
+			return node.final
+		}
+
+		found := false
+		for j := uint(0); j < node.GetChildCount(); j++ {
+			child := node.GetChild(j)
+			if child.letter == key[i] {
+				node = child
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return false
+}
+
+/*
+*
+
+	Returns false if certainly no inserted key k has lo <= k <= hi, where
+	the bounds and the keys are compared once normalized.
+*/
+func (rf *RangeFilter) MayContainRange(lo, hi string) bool {
+	lo = rf.ftm.Ft.normalizeWord(lo)
+	hi = rf.ftm.Ft.normalizeWord(hi)
+	if lo > hi {
+		return false
+	}
+	prefix, node, found := rf.seek(rf.ftm.Ft.GetRoot(), nil, lo, true)
+	if !found || string(prefix) > hi {
+		return false
+	}
+
+	// the truncated key is a prefix of hi: the real suffix may tell that
+	// the key is past hi.
+	if rf.suffixType == RealSuffix && node.childCount == 0 &&
+		strings.HasPrefix(hi, string(prefix)) {
+		return rf.storedSuffix(node) <= rf.getSuffix(hi, uint(len(prefix)))
+	}
+	return true
+}
+
+// Finds the first stored prefix, in sorted order, in the subtree of node
+// whose key may be lo or greater. onPath tells whether prefix, the path to
+// node, is a prefix of lo.
+func (rf *RangeFilter) seek(node FrozenTrieNode, prefix []byte, lo string, onPath bool) ([]byte, FrozenTrieNode, bool) {
+	depth := len(prefix)
+	if node.final {
+		switch {
+		case !onPath || depth == len(lo):
+			return prefix, node, true
+		case node.childCount == 0:
+			// a truncated key on the path of lo: the key may still be
+			// greater than lo unless the real suffix says otherwise.
+			if rf.suffixType != RealSuffix ||
+				rf.storedSuffix(node) >= rf.getSuffix(lo, uint(depth)) {
+				return prefix, node, true
+			}
+		}
+	}
+
+	for j := uint(0); j < node.GetChildCount(); j++ {
+		child := node.GetChild(j)
+		childOnPath := onPath && depth < len(lo)
+		if childOnPath {
+			if child.letter < lo[depth] {
+				continue
+			}
+			childOnPath = child.letter == lo[depth]
+		}
+		childPrefix := append(prefix[:depth:depth], child.letter)
+		if result, last, found := rf.seek(child, childPrefix, lo, childOnPath); found {
+			return result, last, true
+		}
+	}
+	return nil, node, false
+}
@@ -0,0 +1,80 @@
+package bits
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomKeys(r *rand.Rand, n int) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for len(keys) < n {
+		key := make([]byte, 1+r.Intn(8))
+		for i := range key {
+			key[i] = byte('a' + r.Intn(6))
+		}
+		if !seen[string(key)] {
+			seen[string(key)] = true
+			keys = append(keys, string(key))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestRangeFilter(t *testing.T) {
+	useByteLetters(t)
+	r := rand.New(rand.NewSource(7))
+	keys := randomKeys(r, 300)
+	queries := randomKeys(r, 1000)
+
+	for _, suffixType := range []SuffixType{NoSuffix, HashSuffix, RealSuffix} {
+		built, err := CreateRangeFilter(keys, NoNormalization, suffixType, 8)
+		assert.Nil(t, err)
+		rf := RangeFilter{}
+		assert.Nil(t, rf.Init(built.GetData()))
+
+		// no false negatives
+		for _, key := range keys {
+			assert.True(t, rf.MayContain(key), key)
+			assert.True(t, rf.MayContainRange(key, key), key)
+		}
+		for i := 0; i+1 < len(queries); i += 2 {
+			lo, hi := queries[i], queries[i+1]
+			j := sort.SearchStrings(keys, lo)
+			if j < len(keys) && keys[j] <= hi {
+				assert.True(t, rf.MayContainRange(lo, hi), lo+".."+hi)
+			}
+		}
+
+		falsePositives := 0
+		for _, query := range queries {
+			j := sort.SearchStrings(keys, query)
+			if (j == len(keys) || keys[j] != query) && rf.MayContain(query) {
+				falsePositives++
+			}
+		}
+		if suffixType != NoSuffix {
+			assert.Less(t, falsePositives, len(queries)/20, suffixType)
+		}
+	}
+
+	rf, err := CreateRangeFilter([]string{"apple", "apricot", "banana", "band"}, NoNormalization, RealSuffix, 16)
+	assert.Nil(t, err)
+	assert.True(t, rf.MayContain("apple"))
+	assert.False(t, rf.MayContain("apply"))
+	assert.False(t, rf.MayContain("cherry"))
+	assert.True(t, rf.MayContainRange("b", "bana"+"z"))
+	assert.False(t, rf.MayContainRange("applf", "aprh"))
+	assert.False(t, rf.MayContainRange("bandz", "zzz"))
+	assert.False(t, rf.MayContainRange("a", "ap"))
+	assert.False(t, rf.MayContainRange("z", "a"))
+
+	_, err = CreateRangeFilter([]string{"b", "a"}, NoNormalization, NoSuffix, 0)
+	assert.NotNil(t, err)
+	_, err = CreateRangeFilter([]string{"a"}, NoNormalization, HashSuffix, 65)
+	assert.NotNil(t, err)
+}
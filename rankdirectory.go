@@ -39,15 +39,19 @@ type RankDirectory struct {
   summarizes.
 */
 func CreateRankDirectory(data string, numBits, l1Size, l2Size uint) RankDirectory {
+	return buildRankDirectory(data, numBits, l1Size, l2Size, BitWriter{}, 0, 0)
+}
+
+// Writes the directory entries from bit p on, p being the start of a Level
+// 1 section, after those already in directory. count1 is the number of 1
+// bits before p.
+func buildRankDirectory(data string, numBits, l1Size, l2Size uint, directory BitWriter, p, count1 uint) RankDirectory {
 	bits := BitString{}
 	bits.Init(data)
-	var p, i uint = 0, 0
-	var count1, count2 uint = 0, 0
+	var i, count2 uint = 0, 0
 	l1bits := uint(math.Ceil(math.Log2(float64(numBits))))
 	l2bits := uint(math.Ceil(math.Log2(float64(l1Size))))
 
-	directory := BitWriter{}
-
 	for p+l2Size <= numBits {
 		count2 += bits.Count(p, l2Size)
 		i += l2Size
@@ -67,6 +71,35 @@ func CreateRankDirectory(data string, numBits, l1Size, l2Size uint) RankDirector
 	return rd
 }
 
+// Builds the directory over the first numBits bits of data, whose first
+// unchanged bits are those rd indexes, copying the entries of the Level 1
+// sections before them if their width is the same.
+func (rd *RankDirectory) extend(data string, numBits, unchanged uint) RankDirectory {
+	var sections uint = 0
+	if uint(math.Ceil(math.Log2(float64(numBits)))) == rd.l1Bits {
+		sections = unchanged / rd.l1Size
+	}
+	directory := BitWriter{}
+	directory.WriteFrom(&rd.directory, 0, sections*rd.sectionBits)
+	var count1 uint = 0
+	if sections > 0 {
+		count1 = rd.directory.Get(sections*rd.sectionBits-rd.l1Bits, rd.l1Bits)
+	}
+	return buildRankDirectory(data, numBits, rd.l1Size, rd.l2Size, directory, sections*rd.l1Size, count1)
+}
+
+/**
+  Returns the length in bytes of the directory CreateRankDirectory builds
+  over numBits bits, so that stored directories can be checked on load.
+*/
+func rankDirectorySize(numBits, l1Size, l2Size uint) uint {
+	blocks := numBits / l2Size
+	l1Entries := blocks / (l1Size / l2Size)
+	l1bits := uint(math.Ceil(math.Log2(float64(numBits))))
+	l2bits := uint(math.Ceil(math.Log2(float64(l1Size))))
+	return (l1Entries*l1bits + (blocks-l1Entries)*l2bits + 7) / 8
+}
+
 func (rd *RankDirectory) Init(directoryData, bitData string, numBits, l1Size, l2Size uint) {
 	rd.directory.Init(directoryData)
 	rd.data.Init(bitData)
@@ -142,3 +175,49 @@ func (rd *RankDirectory) Select(which, y uint) uint {
 
 	return uint(val)
 }
+
+/**
+  Returns the number of 1 bits up to and including position x.
+*/
+func (rd *RankDirectory) Rank1(x uint) uint {
+	return rd.Rank(1, x)
+}
+
+/**
+  Returns the number of 0 bits up to and including position x.
+*/
+func (rd *RankDirectory) Rank0(x uint) uint {
+	return rd.Rank(0, x)
+}
+
+/**
+  Returns the position of the y'th 1 bit.
+*/
+func (rd *RankDirectory) Select1(y uint) uint {
+	return rd.Select(1, y)
+}
+
+/**
+  Returns the position of the y'th 0 bit.
+*/
+func (rd *RankDirectory) Select0(y uint) uint {
+	return rd.Select(0, y)
+}
+
+/**
+  Returns the number of bits indexed by the directory.
+*/
+func (rd *RankDirectory) Len() uint {
+	return rd.numBits
+}
+
+/**
+  Returns the size of the indexed bits plus the directory, in bits.
+*/
+func (rd *RankDirectory) SizeInBits() uint {
+	return rd.numBits + rd.directory.length
+}
+
+func (rd *RankDirectory) Kind() RankSelectKind {
+	return RankDirectoryKind
+}
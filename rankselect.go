@@ -0,0 +1,150 @@
+package bits
+
+import "fmt"
+
+/*
+*
+
+	RankSelect answers rank and select queries over a bit string. The
+	FrozenTrie uses it to navigate the LOUDS encoding of the trie, and the
+	FrozenTrieMap to number the final nodes.
+
+	Rank counts bits up to and including the given position; select takes a
+	1-based count and returns the position of that bit. This is the
+	convention of RankDirectory.
+*/
+type RankSelect interface {
+	Rank1(x uint) uint
+	Rank0(x uint) uint
+	Select1(y uint) uint
+	Select0(y uint) uint
+
+	// The number of bits indexed.
+	Len() uint
+
+	// The space taken by the bits and the index together.
+	SizeInBits() uint
+
+	Kind() RankSelectKind
+
+	// The encoded index. Kinds that index a bit string held elsewhere, like
+	// RankDirectory, do not include the bits themselves.
+	GetData() string
+}
+
+/*
+*
+
+	RankSelectKind identifies an implementation of RankSelect in the
+	serialized form of a structure.
+*/
+type RankSelectKind uint8
+
+const (
+	// RankDirectory, with the two level L1/L2 layout.
+	RankDirectoryKind RankSelectKind = iota
+	// PlainRankSelect, with a dense table of rank samples.
+	PlainKind
+	// RRR, compressed.
+	RRRKind
+)
+
+/*
+*
+
+	Builds a RankSelect of the given kind over the first numBits bits of
+	data. Panics if the kind is not one of the constants above.
+*/
+func CreateRankSelect(kind RankSelectKind, data string, numBits uint) RankSelect {
+	switch kind {
+	case PlainKind:
+		prs := CreatePlainRankSelect(data, numBits)
+		return &prs
+	case RRRKind:
+		rrr := CreateRRR(data, numBits)
+		return &rrr
+	case RankDirectoryKind:
+		rd := CreateRankDirectory(data, numBits, L1, L2)
+		return &rd
+	}
+	panic(fmt.Sprintf("bits: unknown rank/select kind %d", kind))
+}
+
+// Builds a RankSelect like rs over the first numBits bits of data, whose
+// first unchanged bits are the same as those rs indexes, reusing the index
+// of rs for them.
+func extendRankSelect(rs RankSelect, data string, numBits, unchanged uint) RankSelect {
+	switch r := rs.(type) {
+	case *RankDirectory:
+		rd := r.extend(data, numBits, unchanged)
+		return &rd
+	case *PlainRankSelect:
+		prs := r.extend(data, numBits, unchanged)
+		return &prs
+	case *RRR:
+		rrr := r.extend(data, numBits, unchanged)
+		return &rrr
+	}
+	return CreateRankSelect(rs.Kind(), data, numBits)
+}
+
+/*
+*
+
+	Restores a RankSelect of the given kind from the index returned by its
+	GetData method and the bits it indexes. Kinds that hold their own bits,
+	like RRR, ignore data.
+*/
+func InitRankSelect(kind RankSelectKind, indexData, data string, numBits uint) (RankSelect, error) {
+	switch kind {
+	case RankDirectoryKind:
+		rd := RankDirectory{}
+		rd.Init(indexData, data, numBits, L1, L2)
+		return &rd, nil
+	case PlainKind:
+		prs := PlainRankSelect{}
+		prs.Init(indexData, data, numBits)
+		return &prs, nil
+	case RRRKind:
+		rrr := RRR{}
+		if err := rrr.Init(indexData); err != nil {
+			return nil, err
+		}
+		return &rrr, nil
+	}
+	return nil, fmt.Errorf("bits: unknown rank/select kind %d", kind)
+}
+
+/*
+*
+
+	Returns the index of rs prefixed with its kind, so that DecodeRankSelect
+	can restore it without knowing the kind in advance.
+*/
+func EncodeRankSelect(rs RankSelect) string {
+	return string([]byte{byte(rs.Kind())}) + rs.GetData()
+}
+
+/*
+*
+
+	Restores a RankSelect from the string returned by EncodeRankSelect and
+	the bits it indexes.
+*/
+func DecodeRankSelect(encoded, data string, numBits uint) (RankSelect, error) {
+	if len(encoded) == 0 {
+		return nil, fmt.Errorf("bits: empty rank/select encoding")
+	}
+	return InitRankSelect(RankSelectKind(encoded[0]), encoded[1:], data, numBits)
+}
+
+// the bits indexed by rs, or "" if its own encoding holds them
+func rankSelectBits(rs RankSelect) string {
+	switch r := rs.(type) {
+	case *RankDirectory:
+		return r.data.GetData()
+	case *PlainRankSelect:
+		return r.data.GetData()
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package bits
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomBits(numBits uint, density float64) string {
+	r := rand.New(rand.NewSource(int64(numBits)))
+	bw := BitWriter{}
+	for i := uint(0); i < numBits; i++ {
+		if r.Float64() < density {
+			bw.Write(1, 1)
+		} else {
+			bw.Write(0, 1)
+		}
+	}
+	return bw.GetData()
+}
+
+func testRankSelect(t *testing.T, kind RankSelectKind, data string, numBits uint) {
+	bits := BitString{}
+	bits.Init(data)
+	built := CreateRankSelect(kind, data, numBits)
+	rs, err := DecodeRankSelect(EncodeRankSelect(built), rankSelectBits(built), numBits)
+	assert.Nil(t, err)
+	assert.Equal(t, kind, rs.Kind())
+	assert.Equal(t, numBits, rs.Len())
+
+	var ones, zeros uint = 0, 0
+	for x := uint(0); x < numBits; x++ {
+		if bits.Get(x, 1) == 1 {
+			ones++
+			require.Equal(t, x, rs.Select1(ones), kind)
+		} else {
+			zeros++
+			require.Equal(t, x, rs.Select0(zeros), kind)
+		}
+		require.Equal(t, ones, rs.Rank1(x), kind)
+		require.Equal(t, zeros, rs.Rank0(x), kind)
+	}
+}
+
+func TestRankSelect(t *testing.T) {
+	for _, numBits := range []uint{1, 63, 64, 65, 1000, 5000} {
+		for _, density := range []float64{0.05, 0.5, 0.95} {
+			data := randomBits(numBits, density)
+			testRankSelect(t, RankDirectoryKind, data, numBits)
+			testRankSelect(t, PlainKind, data, numBits)
+			testRankSelect(t, RRRKind, data, numBits)
+		}
+	}
+
+	_, err := DecodeRankSelect("\xff", "", 0)
+	assert.NotNil(t, err)
+	assert.Panics(t, func() { CreateRankSelect(RankSelectKind(0xff), "", 0) })
+}
+
+func TestExtendRankSelect(t *testing.T) {
+	for _, sizes := range [][2]uint{{1, 100}, {1000, 1100}, {5000, 6000}, {5000, 9000}, {20000, 20001}} {
+		old, added := sizes[0], sizes[1]
+		oldData := randomBits(old, 0.5)
+		oldBits := BitString{}
+		oldBits.Init(oldData)
+		other := BitString{}
+		other.Init(randomBits(added, 0.3))
+
+		for _, unchanged := range []uint{0, old / 2, old} {
+			bw := BitWriter{}
+			bw.WriteFrom(&oldBits, 0, unchanged)
+			bw.WriteFrom(&other, unchanged, added-unchanged)
+			data := bw.GetData()
+			for _, kind := range []RankSelectKind{RankDirectoryKind, PlainKind, RRRKind} {
+				extended := extendRankSelect(CreateRankSelect(kind, oldData, old), data, added, unchanged)
+				assert.Equal(t, CreateRankSelect(kind, data, added).GetData(), extended.GetData(), kind, sizes, unchanged)
+			}
+		}
+	}
+}
+
+func TestMapWithKinds(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertInAlphabeticalOrder(&te)
+	teData, _ := te.Encode()
+
+	built := FrozenTrieMap{}
+	built.CreateWithKinds(teData, te.GetNodeCount(), PlainKind, RRRKind)
+	assert.Equal(t, PlainKind, built.Ft.GetDirectory().Kind())
+
+	ftm := FrozenTrieMap{}
+	assert.Nil(t, ftm.InitFromData(built.GetData()))
+	assert.Equal(t, uint(7), ftm.GetKeyCount())
+	for _, word := range []string{"alphapha", "apple", "hello", "jello", "lamp", "orange", "quiz"} {
+		index, found := ftm.LookupIndex(word)
+		assert.True(t, found)
+		assert.Equal(t, word, ftm.ReverseLookup(index))
+		assert.True(t, ftm.Ft.Lookup(word))
+	}
+	assert.False(t, ftm.Ft.Lookup("appl"))
+}
+
+func TestRRR(t *testing.T) {
+	for class := uint(0); class <= RRRBlockSize; class++ {
+		for offset := uint(0); offset < binomial[RRRBlockSize][class]; offset += 7 {
+			value := getRRRBlock(class, offset)
+			require.Equal(t, offset, getRRROffset(value, class), class)
+		}
+	}
+
+	sparse := randomBits(10000, 0.02)
+	rrr := CreateRRR(sparse, 10000)
+	rd := CreateRankDirectory(sparse, 10000, L1, L2)
+	assert.Less(t, rrr.SizeInBits(), rd.SizeInBits())
+	smallest := CreateSmallestRankSelect(sparse, 10000, RankDirectoryKind, PlainKind, RRRKind)
+	assert.Equal(t, RRRKind, smallest.Kind())
+}
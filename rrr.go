@@ -0,0 +1,393 @@
+package bits
+
+import "fmt"
+
+/*
+*
+
+	Parameters of the RRR encoding: the bits are cut into blocks of
+	RRRBlockSize bits, and every RRRSuperblockSize blocks a sample of the
+	rank and of the position in the offset stream is kept.
+*/
+const (
+	RRRBlockSize      uint = 15
+	RRRSuperblockSize uint = 32
+)
+
+// binomial[n][k] is n choose k, for n up to RRRBlockSize
+var binomial = getBinomialTable(RRRBlockSize)
+
+// the number of bits needed for the offset of a block with c 1 bits
+var rrrOffsetBits = getRRROffsetBits()
+
+/*
+*
+
+	RRR is a compressed bit vector with rank and select, after Raman, Raman
+	and Rao. Each block of RRRBlockSize bits is stored as its class, the
+	number of 1 bits in it, and its offset, the index of the block among all
+	blocks of that class. All-0 and all-1 blocks take no offset bits at all,
+	so sparse or dense bit strings, like the bitmap of final nodes in a
+	FrozenTrieMap, shrink well below one bit per bit.
+
+	Unlike RankDirectory, the encoding replaces the bits it indexes, so
+	GetData returns everything needed to restore it.
+*/
+type RRR struct {
+	classes     BitString
+	offsets     BitString
+	samples     BitString
+	rankWidth   uint
+	offsetWidth uint
+	numOffsets  uint
+	numBits     uint
+}
+
+/*
+*
+
+	Encodes the first numBits bits of data.
+*/
+func CreateRRR(data string, numBits uint) RRR {
+	return buildRRR(data, numBits, rrrPrefix{})
+}
+
+// The encoding of the superblocks before some block, to be continued.
+type rrrPrefix struct {
+	classes, offsets BitWriter
+	ranks, positions []uint
+	block, rank      uint
+}
+
+// Encodes the blocks of the first numBits bits of data from prefix.block,
+// the start of a superblock, on, after those of prefix.
+func buildRRR(data string, numBits uint, prefix rrrPrefix) RRR {
+	bits := BitString{}
+	bits.Init(data)
+
+	classes := prefix.classes
+	offsets := prefix.offsets
+	ranks, positions := prefix.ranks, prefix.positions
+	rank := prefix.rank
+
+	for p, block := prefix.block*RRRBlockSize, prefix.block; p < numBits; p, block = p+RRRBlockSize, block+1 {
+		if block%RRRSuperblockSize == 0 {
+			ranks = append(ranks, rank)
+			positions = append(positions, offsets.Len())
+		}
+
+		n := RRRBlockSize
+		if numBits-p < n {
+			n = numBits - p
+		}
+		// pad the last block with 0 bits
+		value := bits.Get(p, n) << (RRRBlockSize - n)
+
+		class := BitsInByte[value>>8] + BitsInByte[value&0xff]
+		classes.Write(class, 4)
+		offsets.Write(getRRROffset(value, class), rrrOffsetBits[class])
+		rank += class
+	}
+
+	rrr := RRR{
+		rankWidth:   getOffsetBits(numBits),
+		offsetWidth: getOffsetBits(offsets.Len()),
+		numOffsets:  offsets.Len(),
+		numBits:     numBits,
+	}
+	samples := BitWriter{}
+	for i := range ranks {
+		samples.Write(ranks[i], rrr.rankWidth)
+		samples.Write(positions[i], rrr.offsetWidth)
+	}
+	rrr.classes.Init(classes.GetData())
+	rrr.offsets.Init(offsets.GetData())
+	rrr.samples.Init(samples.GetData())
+	return rrr
+}
+
+// Encodes the first numBits bits of data, whose first unchanged bits are
+// those rrr encodes, copying the superblocks before them.
+func (rrr *RRR) extend(data string, numBits, unchanged uint) RRR {
+	superblocks := unchanged / (RRRBlockSize * RRRSuperblockSize)
+	if superblocks > 0 && superblocks >= rrr.numSuperblocks() {
+		superblocks = rrr.numSuperblocks() - 1
+	}
+
+	prefix := rrrPrefix{block: superblocks * RRRSuperblockSize}
+	for s := uint(0); s < superblocks; s++ {
+		rank, position := rrr.sample(s)
+		prefix.ranks = append(prefix.ranks, rank)
+		prefix.positions = append(prefix.positions, position)
+	}
+	var position uint = 0
+	if superblocks > 0 {
+		prefix.rank, position = rrr.sample(superblocks)
+	}
+	prefix.classes.WriteFrom(&rrr.classes, 0, prefix.block*4)
+	prefix.offsets.WriteFrom(&rrr.offsets, 0, position)
+	return buildRRR(data, numBits, prefix)
+}
+
+/*
+*
+
+	Restores the bit vector from the string returned by GetData.
+*/
+func (rrr *RRR) Init(data string) error {
+	sections, err := splitSections(data, 4)
+	if err != nil {
+		return err
+	}
+	if len(sections[0]) < 8 {
+		return fmt.Errorf("rrr: header too short")
+	}
+	header := BitString{}
+	header.Init(sections[0])
+	rrr.numBits = header.Get(0, 32)
+	rrr.numOffsets = header.Get(32, 32)
+	rrr.rankWidth = getOffsetBits(rrr.numBits)
+	rrr.offsetWidth = getOffsetBits(rrr.numOffsets)
+	rrr.classes.Init(sections[1])
+	rrr.offsets.Init(sections[2])
+	rrr.samples.Init(sections[3])
+	return nil
+}
+
+/*
+*
+
+	Returns the encoded bit vector, with its samples.
+*/
+func (rrr *RRR) GetData() string {
+	header := BitWriter{}
+	header.Write(rrr.numBits, 32)
+	header.Write(rrr.numOffsets, 32)
+	return joinSections(header.GetData(), rrr.classes.GetData(),
+		rrr.offsets.GetData(), rrr.samples.GetData())
+}
+
+func (rrr *RRR) Kind() RankSelectKind {
+	return RRRKind
+}
+
+/*
+*
+
+	Returns the number of bits encoded.
+*/
+func (rrr *RRR) Len() uint {
+	return rrr.numBits
+}
+
+/*
+*
+
+	Returns the size of the classes, offsets and samples, in bits.
+*/
+func (rrr *RRR) SizeInBits() uint {
+	return rrr.numBlocks()*4 + rrr.numOffsets +
+		rrr.numSuperblocks()*(rrr.rankWidth+rrr.offsetWidth)
+}
+
+func (rrr *RRR) numBlocks() uint {
+	return (rrr.numBits + RRRBlockSize - 1) / RRRBlockSize
+}
+
+func (rrr *RRR) numSuperblocks() uint {
+	return (rrr.numBlocks() + RRRSuperblockSize - 1) / RRRSuperblockSize
+}
+
+// the rank before superblock s, and the position of its first offset
+func (rrr *RRR) sample(s uint) (rank, position uint) {
+	p := s * (rrr.rankWidth + rrr.offsetWidth)
+	return rrr.samples.Get(p, rrr.rankWidth),
+		rrr.samples.Get(p+rrr.rankWidth, rrr.offsetWidth)
+}
+
+func (rrr *RRR) class(block uint) uint {
+	return rrr.classes.Get(block*4, 4)
+}
+
+// skips from the start of the superblock to the given block, returning the
+// rank before it and the position of its offset.
+func (rrr *RRR) seek(block uint) (rank, position uint) {
+	rank, position = rrr.sample(block / RRRSuperblockSize)
+	for b := block - block%RRRSuperblockSize; b < block; b++ {
+		class := rrr.class(b)
+		rank += class
+		position += rrrOffsetBits[class]
+	}
+	return rank, position
+}
+
+// the bits of a block, decoded from its class and offset
+func (rrr *RRR) decode(block, position uint) uint {
+	class := rrr.class(block)
+	offset := rrr.offsets.Get(position, rrrOffsetBits[class])
+	return getRRRBlock(class, offset)
+}
+
+/*
+*
+
+	Returns the number of 1 bits up to and including position x.
+*/
+func (rrr *RRR) Rank1(x uint) uint {
+	block := x / RRRBlockSize
+	rank, position := rrr.seek(block)
+	value := rrr.decode(block, position) >> (RRRBlockSize - x%RRRBlockSize - 1)
+	return rank + BitsInByte[value>>8] + BitsInByte[value&0xff]
+}
+
+/*
+*
+
+	Returns the number of 0 bits up to and including position x.
+*/
+func (rrr *RRR) Rank0(x uint) uint {
+	return x + 1 - rrr.Rank1(x)
+}
+
+/*
+*
+
+	Returns the position of the y'th 1 bit.
+*/
+func (rrr *RRR) Select1(y uint) uint {
+	return rrr.selectBit(1, y)
+}
+
+/*
+*
+
+	Returns the position of the y'th 0 bit.
+*/
+func (rrr *RRR) Select0(y uint) uint {
+	return rrr.selectBit(0, y)
+}
+
+func (rrr *RRR) selectBit(which, y uint) uint {
+	count := func(class, bits uint) uint {
+		if which == 1 {
+			return class
+		}
+		return bits - class
+	}
+	before := func(s uint) uint {
+		rank, _ := rrr.sample(s)
+		return count(rank, s*RRRSuperblockSize*RRRBlockSize)
+	}
+
+	// find the last superblock with fewer than y matching bits before it.
+	low, high := uint(0), rrr.numSuperblocks()
+	if high == 0 {
+		return ^uint(0)
+	}
+	for high-low > 1 {
+		probe := (low + high) / 2
+		if before(probe) < y {
+			low = probe
+		} else {
+			high = probe
+		}
+	}
+
+	seen := before(low)
+	_, position := rrr.sample(low)
+	for block := low * RRRSuperblockSize; block < rrr.numBlocks(); block++ {
+		class := rrr.class(block)
+		inBlock := count(class, RRRBlockSize)
+		if seen+inBlock < y {
+			seen += inBlock
+			position += rrrOffsetBits[class]
+			continue
+		}
+
+		value := rrr.decode(block, position)
+		for i := uint(0); i < RRRBlockSize; i++ {
+			if (value>>(RRRBlockSize-i-1))&1 == which {
+				seen++
+				if seen == y {
+					p := block*RRRBlockSize + i
+					if p >= rrr.numBits {
+						break
+					}
+					return p
+				}
+			}
+		}
+		break
+	}
+
+	// like RankDirectory, return -1 converted to uint when there is no
+	// such bit.
+	return ^uint(0)
+}
+
+/*
+*
+
+	Builds a RankSelect of each of the given kinds and returns the smallest,
+	as measured by SizeInBits.
+*/
+func CreateSmallestRankSelect(data string, numBits uint, kinds ...RankSelectKind) RankSelect {
+	var smallest RankSelect
+	for _, kind := range kinds {
+		rs := CreateRankSelect(kind, data, numBits)
+		if smallest == nil || rs.SizeInBits() < smallest.SizeInBits() {
+			smallest = rs
+		}
+	}
+	return smallest
+}
+
+// enumerative code of a block: its rank among the blocks with the same
+// number of 1 bits, in increasing order of value.
+func getRRROffset(value, class uint) uint {
+	var offset uint = 0
+	for i := RRRBlockSize; i > 0 && class > 0; i-- {
+		if value&(1<<(i-1)) != 0 {
+			// all blocks with a 0 here and class 1 bits in the
+			// remaining positions come first.
+			offset += binomial[i-1][class]
+			class--
+		}
+	}
+	return offset
+}
+
+func getRRRBlock(class, offset uint) uint {
+	var value uint = 0
+	for i := RRRBlockSize; i > 0 && class > 0; i-- {
+		if offset >= binomial[i-1][class] {
+			offset -= binomial[i-1][class]
+			value |= 1 << (i - 1)
+			class--
+		}
+	}
+	return value
+}
+
+func getBinomialTable(n uint) [][]uint {
+	table := make([][]uint, n+1)
+	for i := range table {
+		table[i] = make([]uint, n+2)
+		table[i][0] = 1
+		for k := 1; k <= i; k++ {
+			table[i][k] = table[i-1][k-1] + table[i-1][k]
+		}
+	}
+	return table
+}
+
+func getRRROffsetBits() []uint {
+	result := make([]uint, RRRBlockSize+1)
+	for class := range result {
+		combinations := binomial[RRRBlockSize][class]
+		for (1 << result[class]) < combinations {
+			result[class]++
+		}
+	}
+	return result
+}
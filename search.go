@@ -4,33 +4,30 @@ package bits
  * Given a word, returns array of words, prefix of which is word
  */
 func (f *FrozenTrie) GetSuggestedWords(word string, limit int) []string {
-	var result []string
-
-	node := f.GetRoot()
+	// find the positions corresponding to the last char of input, one for
+	// each spelling of it.
+	return f.suggest(f.descend(word), limit)
+}
 
-	// find the node corresponding to the last char of input
-	wordBytes := []byte(word)
-	for _, i := range wordBytes {
-		var child FrozenTrieNode
-		var j uint = 0
-		for ; j < node.GetChildCount(); j++ {
-			child = node.GetChild(j)
-			if child.letter == i {
-				break
-			}
+// Returns the words continuing the spellings that reached the matches.
+func (f *FrozenTrie) suggest(matches []descent, limit int) []string {
+	var result []string
+	for _, match := range matches {
+		if len(result) > limit {
+			break
 		}
 
-		// not found, return.
-		if j == node.GetChildCount() {
-			return result
+		// the input ends inside the tail of a leaf.
+		if p := match.position; p.tail != "" {
+			result = append(result, match.spelling+p.tail[p.tailPos:])
+			continue
 		}
 
-		node = child
+		// Use the node corresponding to the last letter as root,
+		// traversing the trie in level order.
+		result = append(result, f.traverseSubTrie(match.position.node, match.spelling, limit-len(result))...)
 	}
-
-	// The node corresponding to the last letter of word is found.
-	// Use this node as root. traversing the trie in level order.
-	return f.traverseSubTrie(node, word, limit)
+	return result
 }
 
 func (f *FrozenTrie) traverseSubTrie(node FrozenTrieNode, prefix string, limit int) []string {
@@ -49,7 +46,7 @@ func (f *FrozenTrie) traverseSubTrie(node FrozenTrieNode, prefix string, limit i
 
 		// if the prefix is a legal word.
 		if nodeNow.final {
-			result = append(result, string(prefixNow))
+			result = append(result, string(prefixNow)+f.getTail(nodeNow))
 			if len(result) > limit {
 				return result
 			}
@@ -0,0 +1,198 @@
+package bits
+
+import (
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+/*
+*
+
+	A Segment is one piece of a segmented text. The text is normalized like
+	the keys of the trie first: Word is the normalized piece, and Start and
+	End are byte offsets into the normalized text. KeyIndex is the index of
+	the word, as returned by the LookupIndex of the trie or map the
+	Segmenter was made from. Known is false for an unknown character, whose
+	KeyIndex is 0.
+*/
+type Segment struct {
+	Word     string
+	Start    uint
+	End      uint
+	KeyIndex uint
+	Known    bool
+}
+
+/*
+*
+
+	SegmentCost returns the cost of a segment. The best segmentation is the
+	one with the least total cost.
+*/
+type SegmentCost func(segment Segment) float64
+
+/*
+*
+
+	A SegmentCost preferring the segmentation with the fewest pieces.
+	Unknown characters cost as much as words.
+*/
+func FewestPieces(segment Segment) float64 {
+	return 1
+}
+
+/*
+*
+
+	Returns a SegmentCost preferring the segmentation with the highest total
+	score, such as a frequency, of its words. Unknown characters score
+	unknownScore.
+*/
+func MaxScore(score func(keyIndex uint) float64, unknownScore float64) SegmentCost {
+	return func(segment Segment) float64 {
+		if !segment.Known {
+			return -unknownScore
+		}
+		return -score(segment.KeyIndex)
+	}
+}
+
+/*
+*
+
+	Segmenter splits text without spaces, like Pali compounds, into the
+	words of a trie, by searching the trie for the words starting at each
+	position.
+*/
+type Segmenter struct {
+	// the cost to minimize. FewestPieces if nil.
+	Cost SegmentCost
+	// if set, a single character that starts no word becomes a segment of
+	// its own, so every text has a segmentation.
+	AllowUnknown bool
+
+	trie     *FrozenTrie
+	keyIndex func(node FrozenTrieNode) uint
+}
+
+/*
+*
+
+	Creates a segmenter over the words of the trie. Key indices are node
+	indices, as returned by FrozenTrie.LookupIndex.
+*/
+func NewSegmenter(ft *FrozenTrie) *Segmenter {
+	return &Segmenter{
+		trie:     ft,
+		keyIndex: func(node FrozenTrieNode) uint { return node.index },
+	}
+}
+
+/*
+*
+
+	Creates a segmenter over the words of the map. Key indices are those of
+	FrozenTrieMap.LookupIndex.
+*/
+func NewMapSegmenter(ftm *FrozenTrieMap) *Segmenter {
+	return &Segmenter{
+		trie:     &ftm.Ft,
+		keyIndex: func(node FrozenTrieNode) uint { return ftm.keys.Rank1(node.index) },
+	}
+}
+
+// Returns the segments starting at start: every word of the trie that
+// text[start:] begins with, after folding if the trie has a folding table,
+// longest first, or the unknown character there if there is none and they
+// are allowed.
+func (s *Segmenter) segmentsAt(text string, start int) []Segment {
+	var result []Segment
+	s.trie.follow(triePosition{node: s.trie.GetRoot()}, text[start:], func(p triePosition, _ string, covered int) {
+		if covered > 0 && p.final() {
+			result = append(result, Segment{
+				Word:     text[start : start+covered],
+				Start:    uint(start),
+				End:      uint(start + covered),
+				KeyIndex: s.keyIndex(p.node),
+				Known:    true,
+			})
+		}
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].End > result[j].End })
+
+	if len(result) == 0 && s.AllowUnknown {
+		_, size := utf8.DecodeRuneInString(text[start:])
+		result = append(result, Segment{
+			Word:  text[start : start+size],
+			Start: uint(start),
+			End:   uint(start + size),
+		})
+	}
+	return result
+}
+
+/*
+*
+
+	Calls fn with every segmentation of text, until fn returns false.
+	Segmentations with longer first words come first. There may be
+	exponentially many.
+*/
+func (s *Segmenter) Enumerate(text string, fn func(segmentation []Segment) bool) {
+	text = s.trie.normalizeWord(text)
+	var current []Segment
+	var visit func(start int) bool
+	visit = func(start int) bool {
+		if start == len(text) {
+			return fn(append([]Segment(nil), current...))
+		}
+		for _, segment := range s.segmentsAt(text, start) {
+			current = append(current, segment)
+			if !visit(int(segment.End)) {
+				return false
+			}
+			current = current[:len(current)-1]
+		}
+		return true
+	}
+	if text != "" {
+		visit(0)
+	}
+}
+
+/*
+*
+
+	Returns the segmentation of text with the least total cost, or false if
+	text cannot be segmented. Among equal costs, longer first words win.
+*/
+func (s *Segmenter) Best(text string) (segmentation []Segment, found bool) {
+	cost := s.Cost
+	if cost == nil {
+		cost = FewestPieces
+	}
+	text = s.trie.normalizeWord(text)
+
+	// best[i] is the least cost of segmenting text[i:], reached by taking
+	// choice[i] first.
+	best := make([]float64, len(text)+1)
+	choice := make([]Segment, len(text)+1)
+	for i := len(text) - 1; i >= 0; i-- {
+		best[i] = math.Inf(1)
+		for _, segment := range s.segmentsAt(text, i) {
+			if total := cost(segment) + best[segment.End]; total < best[i] {
+				best[i] = total
+				choice[i] = segment
+			}
+		}
+	}
+
+	if math.IsInf(best[0], 1) {
+		return nil, false
+	}
+	for i := 0; i < len(text); i = int(choice[i].End) {
+		segmentation = append(segmentation, choice[i])
+	}
+	return segmentation, true
+}
@@ -0,0 +1,82 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func words(segmentation []Segment) []string {
+	var result []string
+	for _, segment := range segmentation {
+		result = append(result, segment.Word)
+	}
+	return result
+}
+
+func TestSegmenter(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	for _, word := range []string{"dhamma", "dhammacakka", "cakka", "ca", "kka", "pavattana", "sutta"} {
+		te.Insert(word)
+	}
+	ftm := createTestMap(&te)
+
+	s := NewMapSegmenter(&ftm)
+	var all [][]string
+	s.Enumerate("dhammacakkapavattanasutta", func(segmentation []Segment) bool {
+		all = append(all, words(segmentation))
+		return true
+	})
+	assert.Equal(t, [][]string{
+		{"dhammacakka", "pavattana", "sutta"},
+		{"dhamma", "cakka", "pavattana", "sutta"},
+		{"dhamma", "ca", "kka", "pavattana", "sutta"},
+	}, all)
+
+	best, found := s.Best("dhammacakkapavattanasutta")
+	assert.True(t, found)
+	assert.Equal(t, all[0], words(best))
+	index, _ := ftm.LookupIndex("sutta")
+	assert.Equal(t, index, best[2].KeyIndex)
+	assert.Equal(t, uint(20), best[2].Start)
+	assert.Equal(t, uint(25), best[2].End)
+
+	// scores prefer more, frequent pieces
+	freq := map[string]float64{"ca": 10, "kka": 10}
+	s.Cost = MaxScore(func(keyIndex uint) float64 {
+		return freq[ftm.ReverseLookup(keyIndex)] + 1
+	}, -100)
+	best, _ = s.Best("dhammacakka")
+	assert.Equal(t, []string{"dhamma", "ca", "kka"}, words(best))
+
+	_, found = s.Best("dhammaxsutta")
+	assert.False(t, found)
+	s.AllowUnknown = true
+	best, found = s.Best("dhammaṃsutta")
+	assert.True(t, found)
+	assert.Equal(t, []string{"dhamma", "ṃ", "sutta"}, words(best))
+	assert.False(t, best[1].Known)
+
+	count := 0
+	s.Enumerate("dhammacakka", func([]Segment) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 1, count)
+
+	// over a trie with tails
+	tailed := freezeTrieWithTails(t, &te)
+	best, found = NewSegmenter(tailed).Best("suttadhammacakka")
+	assert.True(t, found)
+	assert.Equal(t, []string{"sutta", "dhammacakka"}, words(best))
+
+	// with a folding table, pieces keep the spelling of the text
+	tailed.SetFolding(NewFoldingTable(PaliFolding))
+	best, found = NewSegmenter(tailed).Best("suttadhāmmacakka")
+	assert.True(t, found)
+	assert.Equal(t, []string{"sutta", "dhāmmacakka"}, words(best))
+	index, _ = tailed.LookupIndex("dhammacakka")
+	assert.Equal(t, index, best[1].KeyIndex)
+}
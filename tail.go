@@ -0,0 +1,177 @@
+package bits
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+*
+
+	Tails holds the suffixes cut off the trie by Trie.EncodeWithTails. A
+	long word ends in a chain of single child nodes, each costing two bits of
+	topology plus dataBits of data; after the cut, the first node of the
+	chain becomes a final leaf and the labels of the rest are stored here as
+	one string, the tail of that leaf.
+
+	A bitmap over the nodes marks the leaves with a tail, and its rank gives
+	the number of the tail. The bitmap is indexed by a RankSelect of the
+	kind chosen when encoding. The tails are concatenated into a pool, with
+	their start offsets in an EliasFano sequence.
+*/
+type Tails struct {
+	hasTail RankSelect
+	offsets EliasFano
+	pool    string
+}
+
+/*
+*
+
+	Encode the trie like Encode, but cut every chain of single child nodes
+	that ends in a leaf and store its labels as a tail. Returns the encoded
+	trie, which has nodeCount nodes, and the tails, to be passed to
+	FrozenTrie.InitTails. kind selects the RankSelect over the bitmap of
+	leaves with a tail.
+*/
+func (t *Trie) EncodeWithTails(kind RankSelectKind) (encoding, tailData string, nodeCount, numKeys uint) {
+	tails := map[*TrieNode]string{}
+	cut := Trie{root: cutTails(t.root, tails), normalizer: t.normalizer}
+	encoding, numKeys = cut.Encode()
+
+	hasTail := BitWriter{}
+	var offsets []uint64
+	var pool strings.Builder
+	cut.Apply(func(node *TrieNode) {
+		nodeCount++
+		tail, ok := tails[node]
+		if !ok {
+			hasTail.Write(0, 1)
+			return
+		}
+		hasTail.Write(1, 1)
+		offsets = append(offsets, uint64(pool.Len()))
+		pool.WriteString(tail)
+	})
+	offsets = append(offsets, uint64(pool.Len()))
+
+	// offsets are non-decreasing, so this cannot fail.
+	ef, _ := CreateEliasFano(offsets)
+	rs := CreateRankSelect(kind, hasTail.GetData(), nodeCount)
+	tailData = joinSections(rankSelectBits(rs), EncodeRankSelect(rs),
+		ef.GetData(), pool.String())
+	return encoding, tailData, nodeCount, numKeys
+}
+
+// Copies the trie below node, replacing chains with tails.
+func cutTails(node *TrieNode, tails map[*TrieNode]string) *TrieNode {
+	result := &TrieNode{letter: node.letter, final: node.final}
+	for _, child := range node.children {
+		if len(child.children) > 0 {
+			if tail, ok := getTail(child); ok {
+				leaf := &TrieNode{letter: child.letter, final: true}
+				tails[leaf] = tail
+				result.children = append(result.children, leaf)
+				continue
+			}
+		}
+		result.children = append(result.children, cutTails(child, tails))
+	}
+	return result
+}
+
+// Returns the labels below node if they form a single path, with only the
+// last node final.
+func getTail(node *TrieNode) (string, bool) {
+	var tail []byte
+	for len(node.children) > 0 {
+		if len(node.children) > 1 || node.final {
+			return "", false
+		}
+		node = node.children[0]
+		tail = append(tail, node.letter)
+	}
+	return string(tail), true
+}
+
+/*
+*
+
+	Restores the tails returned by Trie.EncodeWithTails for a trie of
+	nodeCount nodes.
+*/
+func (tl *Tails) Init(data string, nodeCount uint) error {
+	sections, err := splitSections(data, 4)
+	if err != nil {
+		return err
+	}
+	hasTail, err := DecodeRankSelect(sections[1], sections[0], nodeCount)
+	if err != nil {
+		return err
+	}
+	tl.hasTail = hasTail
+	if err := tl.offsets.Init(sections[2]); err != nil {
+		return err
+	}
+	tl.pool = sections[3]
+	return nil
+}
+
+/*
+*
+
+	Returns the encoded tails.
+*/
+func (tl *Tails) GetData() string {
+	return joinSections(rankSelectBits(tl.hasTail), EncodeRankSelect(tl.hasTail),
+		tl.offsets.GetData(), tl.pool)
+}
+
+/*
+*
+
+	Returns the tail of the node with the given index, if it has one.
+*/
+func (tl *Tails) Get(index uint) (string, bool) {
+	if index >= tl.hasTail.Len() {
+		return "", false
+	}
+	var i uint = 0
+	if index > 0 {
+		i = tl.hasTail.Rank1(index - 1)
+	}
+	if tl.hasTail.Rank1(index) == i {
+		return "", false
+	}
+	return tl.pool[tl.offsets.Get(i):tl.offsets.Get(i+1)], true
+}
+
+/*
+*
+
+	Makes the trie compare against the tails returned by
+	Trie.EncodeWithTails, which must have encoded this trie. Lookup,
+	GetSuggestedWords and FrozenTrieMap.ReverseLookup then handle cut words
+	transparently.
+*/
+func (f *FrozenTrie) InitTails(tailData string) error {
+	tails := &Tails{}
+	if err := tails.Init(tailData, f.GetNodeCount()); err != nil {
+		return fmt.Errorf("tails: %v", err)
+	}
+	f.tails = tails
+	return nil
+}
+
+/*
+*
+
+	Returns the tail of the node, or "" if it has none.
+*/
+func (f *FrozenTrie) getTail(node FrozenTrieNode) string {
+	if f.tails == nil || node.childCount > 0 {
+		return ""
+	}
+	tail, _ := f.tails.Get(node.index)
+	return tail
+}
@@ -0,0 +1,50 @@
+package bits
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTails(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertNotInAlphabeticalOrder(&te)
+	te.Insert("app")
+	te.Insert("applesauce")
+
+	for _, kind := range []RankSelectKind{RankDirectoryKind, PlainKind, RRRKind} {
+		encoding, tailData, nodeCount, numKeys := te.EncodeWithTails(kind)
+		assert.Equal(t, uint(9), numKeys)
+		assert.Less(t, nodeCount, te.GetNodeCount())
+
+		built := FrozenTrieMap{}
+		built.Create(encoding, nodeCount)
+		assert.Nil(t, built.Ft.InitTails(tailData))
+
+		ftm := FrozenTrieMap{}
+		assert.Nil(t, ftm.InitFromData(built.GetData()))
+		ft := &ftm.Ft
+
+		words := []string{"alphapha", "app", "apple", "applesauce", "hello", "jello", "lamp", "orange", "quiz"}
+		for _, word := range words {
+			assert.True(t, ft.Lookup(word), word)
+			index, found := ftm.LookupIndex(word)
+			assert.True(t, found, word)
+			assert.Equal(t, word, ftm.ReverseLookup(index))
+		}
+		for _, word := range []string{"", "a", "appl", "applea", "hell", "helloo", "hellp", "q", "quizz"} {
+			assert.False(t, ft.Lookup(word), word)
+		}
+
+		assert.Equal(t, []string{"hello"}, ft.GetSuggestedWords("hel", 10))
+		assert.Equal(t, []string{"hello"}, ft.GetSuggestedWords("hello", 10))
+		assert.Nil(t, ft.GetSuggestedWords("helm", 10))
+		suggested := ft.GetSuggestedWords("a", 10)
+		sort.Strings(suggested)
+		assert.Equal(t, []string{"alphapha", "app", "apple", "applesauce"}, suggested)
+		assert.Equal(t, "quiz", ft.GetLastLexographicKey())
+	}
+}
@@ -2,7 +2,10 @@
 // structure in Go.
 package bits
 
-import "unicode/utf8"
+import (
+	"sort"
+	"unicode/utf8"
+)
 
 // https://blog.golang.org/strings
 // https://golang.org/pkg/unicode/utf8/
@@ -24,6 +27,8 @@ type Trie struct {
 	root         *TrieNode
 	cache        []*TrieNode
 	nodeCount    uint
+	keyCount     uint
+	normalizer
 }
 
 func (t *Trie) Init() {
@@ -34,6 +39,7 @@ func (t *Trie) Init() {
 	}
 	t.cache = append(t.cache, t.root)
 	t.nodeCount = 1
+	t.keyCount = 0
 }
 
 /*
@@ -52,6 +58,7 @@ func (t *Trie) GetNodeCount() uint {
 	inserted in alphabetical order.
 */
 func (t *Trie) Insert(word string) {
+	word = t.normalizeWord(word)
 
 	commonPrefixWidth := 0
 	commonRuneCount := 0
@@ -99,10 +106,179 @@ func (t *Trie) Insert(word string) {
 		node = next
 	}
 
-	node.final = true
+	if !node.final {
+		node.final = true
+		t.keyCount++
+	}
 	t.previousWord = word
 }
 
+/*
+*
+
+	Removes a word from the trie, along with the nodes that no longer lead
+	to any word. Returns false if the word was not in the trie.
+*/
+func (t *Trie) Delete(word string) bool {
+	word = t.normalizeWord(word)
+	path := []*TrieNode{t.root}
+	for i := 0; i < len(word); i++ {
+		child := path[len(path)-1].getChild(word[i])
+		if child == nil {
+			return false
+		}
+		path = append(path, child)
+	}
+	node := path[len(path)-1]
+	if !node.final {
+		return false
+	}
+	node.final = false
+	t.keyCount--
+
+	for i := len(path) - 1; i > 0 && !path[i].final && len(path[i].children) == 0; i-- {
+		parent := path[i-1]
+		for j, child := range parent.children {
+			if child == path[i] {
+				parent.children = append(parent.children[:j], parent.children[j+1:]...)
+				break
+			}
+		}
+		t.nodeCount--
+	}
+
+	// the cached path of the previous word may hold pruned nodes, so make
+	// the next Insert start from the root.
+	t.previousWord = ""
+	t.cache = t.cache[:1]
+	return true
+}
+
+/*
+*
+
+	Returns the number of words in the trie.
+*/
+func (t *Trie) Len() uint {
+	return t.keyCount
+}
+
+// Returns the node reached by following word from the root, or nil.
+func (t *Trie) find(word string) *TrieNode {
+	word = t.normalizeWord(word)
+	node := t.root
+	for i := 0; i < len(word) && node != nil; i++ {
+		node = node.getChild(word[i])
+	}
+	return node
+}
+
+// Returns the child with the given letter, or nil.
+func (f *TrieNode) getChild(letter byte) *TrieNode {
+	for _, child := range f.children {
+		if child.letter == letter {
+			return child
+		}
+	}
+	return nil
+}
+
+/*
+*
+
+	Returns true if and only if the word was inserted.
+*/
+func (t *Trie) Contains(word string) bool {
+	node := t.find(word)
+	return node != nil && node.final
+}
+
+/*
+*
+
+	Returns true if some inserted word starts with prefix.
+*/
+func (t *Trie) HasPrefix(prefix string) bool {
+	node := t.find(prefix)
+	if node == nil {
+		return false
+	}
+	// the root has no word below it when the trie is empty.
+	return node != t.root || t.keyCount > 0
+}
+
+/*
+*
+
+	Given a prefix, returns array of words starting with it, in the same
+	order and with the same limit as FrozenTrie.GetSuggestedWords.
+*/
+func (t *Trie) Suggest(prefix string, limit int) []string {
+	var result []string
+	node := t.find(prefix)
+	if node == nil {
+		return result
+	}
+	prefix = t.normalizeWord(prefix)
+
+	level := []*TrieNode{node}
+	prefixLevel := []string{prefix}
+	for len(level) > 0 {
+		nodeNow := level[0]
+		level = level[1:]
+		prefixNow := prefixLevel[0]
+		prefixLevel = prefixLevel[1:]
+
+		// if the prefix is a legal word.
+		if nodeNow.final {
+			result = append(result, prefixNow)
+			if len(result) > limit {
+				return result
+			}
+		}
+
+		for _, child := range nodeNow.children {
+			level = append(level, child)
+			prefixLevel = append(prefixLevel, prefixNow+string([]byte{child.letter}))
+		}
+	}
+	return result
+}
+
+/*
+*
+
+	Calls fn with every word starting with prefix, in sorted order, until fn
+	returns false.
+*/
+func (t *Trie) Iterate(prefix string, fn func(word string) bool) {
+	node := t.find(prefix)
+	if node == nil {
+		return
+	}
+	prefix = t.normalizeWord(prefix)
+	node.iterate([]byte(prefix), fn)
+}
+
+// Calls fn with the words in the subtree of the node, whose path is word,
+// in sorted order. Returns false if fn stopped the iteration.
+func (f *TrieNode) iterate(word []byte, fn func(word string) bool) bool {
+	if f.final && !fn(string(word)) {
+		return false
+	}
+	children := make([]*TrieNode, len(f.children))
+	copy(children, f.children)
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].letter < children[j].letter
+	})
+	for _, child := range children {
+		if !child.iterate(append(word, child.letter), fn) {
+			return false
+		}
+	}
+	return true
+}
+
 /*
 *
 
@@ -161,6 +337,7 @@ func (t *Trie) Encode() (encoding string, numKeys uint) {
 
 		bits.Write(uint(node.letter), dataBits-1)
 	})
+	t.writeTrailer(&bits)
 
 	return bits.GetData(), numKeys
 }
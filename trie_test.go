@@ -1,6 +1,11 @@
 package bits
 
-import "testing"
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func insertInAlphabeticalOrder(te *Trie) {
 	te.Insert("alphapha")
@@ -22,6 +27,47 @@ func insertNotInAlphabeticalOrder(te *Trie) {
 	te.Insert("quiz")
 }
 
+// useByteLetters stores whole bytes as node letters for the duration of the
+// test, whatever alphabet an earlier test left behind.
+func useByteLetters(t *testing.T) {
+	old := dataBits
+	dataBits = 9
+	t.Cleanup(func() { dataBits = old })
+}
+
+// freezeTrie encodes te and returns it frozen, with a RankDirectory over its
+// topology.
+func freezeTrie(te *Trie) *FrozenTrie {
+	teData, _ := te.Encode()
+	rd := CreateRankDirectory(teData, te.GetNodeCount()*2+1, L1, L2)
+	ft := &FrozenTrie{}
+	ft.Init(teData, rd.GetData(), te.GetNodeCount())
+	return ft
+}
+
+// freezeTrieWithTails is like freezeTrie, with the chains ending in a leaf
+// cut into tails.
+func freezeTrieWithTails(t *testing.T, te *Trie) *FrozenTrie {
+	encoding, tailData, nodeCount, _ := te.EncodeWithTails(RankDirectoryKind)
+	rd := CreateRankDirectory(encoding, nodeCount*2+1, L1, L2)
+	ft := &FrozenTrie{}
+	ft.Init(encoding, rd.GetData(), nodeCount)
+	assert.Nil(t, ft.InitTails(tailData))
+	return ft
+}
+
+// freezeWords stores whole bytes as letters for the duration of the test,
+// and returns a frozen trie of the words, inserted in the order given.
+func freezeWords(t *testing.T, words ...string) *FrozenTrie {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	for _, word := range words {
+		te.Insert(word)
+	}
+	return freezeTrie(&te)
+}
+
 func TestTrie(t *testing.T) {
 	te := Trie{}
 	te.Init()
@@ -41,3 +87,80 @@ func TestTrie(t *testing.T) {
 	}
 	t.Log(rd.GetData())
 }
+
+func TestTrieQueries(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	assert.False(t, te.HasPrefix(""))
+	assert.Equal(t, uint(0), te.Len())
+	insertNotInAlphabeticalOrder(&te)
+	te.Insert("apple")
+	te.Insert("app")
+
+	assert.Equal(t, uint(8), te.Len())
+	for _, word := range []string{"apple", "app", "quiz", "hello"} {
+		assert.True(t, te.Contains(word), word)
+	}
+	for _, word := range []string{"ap", "apples", "", "zebra"} {
+		assert.False(t, te.Contains(word), word)
+	}
+	assert.True(t, te.HasPrefix("ap"))
+	assert.True(t, te.HasPrefix(""))
+	assert.False(t, te.HasPrefix("b"))
+
+	ft := freezeTrie(&te)
+	for _, prefix := range []string{"", "a", "ap", "x"} {
+		assert.Equal(t, ft.GetSuggestedWords(prefix, 3), te.Suggest(prefix, 3), prefix)
+	}
+
+	var words []string
+	te.Iterate("", func(word string) bool {
+		words = append(words, word)
+		return true
+	})
+	assert.Len(t, words, 8)
+	assert.True(t, sort.StringsAreSorted(words))
+	words = nil
+	te.Iterate("a", func(word string) bool {
+		words = append(words, word)
+		return len(words) < 2
+	})
+	assert.Equal(t, []string{"alphapha", "app"}, words)
+}
+
+func TestTrieDelete(t *testing.T) {
+	useByteLetters(t)
+	te := Trie{}
+	te.Init()
+	insertInAlphabeticalOrder(&te)
+	te.Insert("quizzes")
+	nodeCount := te.GetNodeCount()
+
+	assert.False(t, te.Delete("quizz"))
+	assert.False(t, te.Delete("zebra"))
+	assert.False(t, te.Delete(""))
+	assert.True(t, te.Delete("quizzes"))
+	assert.False(t, te.Contains("quizzes"))
+	assert.True(t, te.Contains("quiz"))
+	assert.Equal(t, nodeCount-3, te.GetNodeCount())
+	assert.Equal(t, uint(7), te.Len())
+	assert.False(t, te.Delete("quizzes"))
+
+	// the encoding matches a trie built without the deleted words
+	te.Delete("apple")
+	te.Delete("quiz")
+	te.Insert("quiet")
+	te.Insert("apricot")
+
+	expected := Trie{}
+	expected.Init()
+	for _, word := range []string{"alphapha", "hello", "jello", "lamp", "orange", "quiet", "apricot"} {
+		expected.Insert(word)
+	}
+	assert.Equal(t, expected.GetNodeCount(), te.GetNodeCount())
+	assert.Equal(t, expected.Len(), te.Len())
+	teData, _ := te.Encode()
+	expectedData, _ := expected.Encode()
+	assert.Equal(t, expectedData, teData)
+}